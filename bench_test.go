@@ -692,3 +692,97 @@ func BenchmarkToSliceSafe(b *testing.B) {
 func BenchmarkToSliceUnsafe(b *testing.B) {
 	benchToSlice(b, NewThreadUnsafeSet[Int]())
 }
+
+func nrandSmall(n Int, max int) []Int {
+	i := make([]Int, n)
+	for ind := range i {
+		i[ind] = Int(rand.Intn(max))
+	}
+	return i
+}
+
+func benchAddSmall(b *testing.B, n Int, newSet func(...Int) Set[Int]) {
+	nums := nrandSmall(n, 1<<16)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := newSet()
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func BenchmarkAddSmallUnsafe(b *testing.B) {
+	benchAddSmall(b, 1000, NewThreadUnsafeSet[Int])
+}
+
+func BenchmarkAddSmallBitSet(b *testing.B) {
+	benchAddSmall(b, 1000, NewThreadUnsafeBitSet[Int])
+}
+
+func benchUnionSmall(b *testing.B, n Int, s, t Set[Int]) {
+	for _, v := range nrandSmall(n, 1<<16) {
+		s.Add(v)
+	}
+	for _, v := range nrandSmall(n, 1<<16) {
+		t.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Union(t)
+	}
+}
+
+func BenchmarkUnionSmallUnsafe(b *testing.B) {
+	benchUnionSmall(b, 1000, NewThreadUnsafeSet[Int](), NewThreadUnsafeSet[Int]())
+}
+
+func BenchmarkUnionSmallBitSet(b *testing.B) {
+	benchUnionSmall(b, 1000, NewThreadUnsafeBitSet[Int](), NewThreadUnsafeBitSet[Int]())
+}
+
+func benchAddKeyed[T any](b *testing.B, nums []T, newSet func(...T) Set[T]) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := newSet()
+		for _, v := range nums {
+			s.Add(v)
+		}
+	}
+}
+
+func keyedInts(nums []Int) []KeyedInt {
+	keyed := make([]KeyedInt, len(nums))
+	for i, v := range nums {
+		keyed[i] = KeyedInt(v)
+	}
+	return keyed
+}
+
+func BenchmarkAddStringKeyUnsafe(b *testing.B) {
+	benchAddKeyed(b, nrand(1000), NewThreadUnsafeSet[Int])
+}
+
+func BenchmarkAddIntKeyUnsafe(b *testing.B) {
+	benchAddKeyed(b, keyedInts(nrand(1000)), NewThreadUnsafeKeyerSet[int, KeyedInt])
+}
+
+func benchContainsKeyed[T any](b *testing.B, nums []T, s Set[T]) {
+	for _, v := range nums {
+		s.Add(v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Contains(nums...)
+	}
+}
+
+func BenchmarkContains100StringKeyUnsafe(b *testing.B) {
+	benchContainsKeyed(b, nrand(100), NewThreadUnsafeSet[Int]())
+}
+
+func BenchmarkContains100IntKeyUnsafe(b *testing.B) {
+	benchContainsKeyed(b, keyedInts(nrand(100)), NewThreadUnsafeKeyerSet[int, KeyedInt]())
+}
@@ -0,0 +1,386 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+)
+
+// BitSetElement is the constraint for elements of bit sets: integer types
+// which also implement EqualKeyer. Bit sets are meant for small
+// non-negative integers such as IDs within a bounded range, as their memory
+// footprint is proportional to the largest element.
+type BitSetElement interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+	EqualKeyer
+}
+
+// MaxBitSetElement is the largest element a bit set can hold, it bounds
+// the memory of a bit set to 2 MiB.
+const MaxBitSetElement = 1<<24 - 1
+
+// NewBitSet creates and returns a new bit set with the given elements.
+// Membership is determined by the integer value of the elements alone, the
+// methods of EqualKeyer are not consulted. Adding a negative element or one
+// greater than MaxBitSetElement panics, use TryAdd to get an error instead.
+// Decoding such elements fails with an error. Operations on the resulting
+// set are thread-safe.
+func NewBitSet[T BitSetElement](vals ...T) Set[T] {
+	s := NewThreadUnsafeBitSet(vals...)
+	return newThreadSafeSet(s)
+}
+
+// NewThreadUnsafeBitSet creates and returns a new bit set with the given
+// elements. Membership is determined by the integer value of the elements
+// alone, the methods of EqualKeyer are not consulted. Adding a negative
+// element or one greater than MaxBitSetElement panics, use TryAdd to get an
+// error instead. Decoding such elements fails with an error. Operations on
+// the resulting set are not thread-safe.
+func NewThreadUnsafeBitSet[T BitSetElement](vals ...T) Set[T] {
+	s := &bitSet[T]{}
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return s
+}
+
+// bitSet stores the element i as bit i%64 of words[i/64]. Unions,
+// intersections and differences with other bit sets are computed word-wise.
+type bitSet[T BitSetElement] struct {
+	words []uint64
+	size  int
+}
+
+func (s *bitSet[T]) emptySet() Set[T] {
+	return &bitSet[T]{}
+}
+
+func (s *bitSet[T]) replaceWith(src Set[T]) bool {
+	o, ok := src.(*bitSet[T])
+	if ok {
+		*s = *o
+	}
+	return ok
+}
+
+// position returns the word index and the bit mask of v. ok is false when v
+// is negative or greater than MaxBitSetElement and hence cannot be stored in
+// the set.
+func (s *bitSet[T]) position(v T) (word int, mask uint64, ok bool) {
+	if v < 0 || uint64(v) > MaxBitSetElement {
+		return 0, 0, false
+	}
+	return int(uint64(v) / 64), 1 << (uint64(v) % 64), true
+}
+
+func (s *bitSet[T]) has(v T) bool {
+	word, mask, ok := s.position(v)
+	return ok && word < len(s.words) && s.words[word]&mask != 0
+}
+
+// trim drops the trailing zero words, so that equal sets always have
+// word slices of the same length.
+func (s *bitSet[T]) trim() {
+	n := len(s.words)
+	for n > 0 && s.words[n-1] == 0 {
+		n--
+	}
+	s.words = s.words[:n]
+}
+
+// count recomputes the cardinality of the set from its words.
+func (s *bitSet[T]) count() {
+	s.size = 0
+	for _, w := range s.words {
+		s.size += bits.OnesCount64(w)
+	}
+}
+
+// Add panics if v is negative or greater than MaxBitSetElement.
+func (s *bitSet[T]) Add(v T) bool {
+	added, err := s.checkedAdd(v, false)
+	if err != nil {
+		panic(err)
+	}
+	return added
+}
+
+func (s *bitSet[T]) checkedAdd(v T, _ bool) (bool, error) {
+	word, mask, ok := s.position(v)
+	switch {
+	case v < 0:
+		return false, fmt.Errorf("%w: negative element %d added to a bit set", ErrInvalidElement, v)
+	case !ok:
+		return false, fmt.Errorf("%w: element %d added to a bit set exceeds %d", ErrCapacityExceeded, v, MaxBitSetElement)
+	}
+	if word >= len(s.words) {
+		s.words = append(s.words, make([]uint64, word+1-len(s.words))...)
+	}
+	if s.words[word]&mask != 0 {
+		return false, nil
+	}
+	s.words[word] |= mask
+	s.size++
+	return true, nil
+}
+
+func (s *bitSet[T]) Cardinality() int {
+	return s.size
+}
+
+func (s *bitSet[T]) Clear() {
+	*s = bitSet[T]{}
+}
+
+func (s *bitSet[T]) Clone() Set[T] {
+	return &bitSet[T]{
+		words: append([]uint64(nil), s.words...),
+		size:  s.size,
+	}
+}
+
+func (s *bitSet[T]) Contains(v ...T) bool {
+	for _, val := range v {
+		if !s.has(val) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *bitSet[T]) Difference(other Set[T]) Set[T] {
+	o, ok := other.(*bitSet[T])
+	if !ok {
+		return difference[T](&bitSet[T]{}, s, other)
+	}
+
+	diff := s.Clone().(*bitSet[T])
+	for i := 0; i < len(diff.words) && i < len(o.words); i++ {
+		diff.words[i] &^= o.words[i]
+	}
+	diff.trim()
+	diff.count()
+	return diff
+}
+
+func (s *bitSet[T]) Each(cb func(T) bool) {
+	for i, w := range s.words {
+		for w != 0 {
+			bit := bits.TrailingZeros64(w)
+			if cb(T(i*64 + bit)) {
+				return
+			}
+			w &^= 1 << bit
+		}
+	}
+}
+
+func (s *bitSet[T]) Equal(other Set[T]) bool {
+	o, ok := other.(*bitSet[T])
+	if !ok {
+		return equal[T](s, other)
+	}
+
+	if s.size != o.size || len(s.words) != len(o.words) {
+		return false
+	}
+	for i, w := range s.words {
+		if w != o.words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *bitSet[T]) Intersect(other Set[T]) Set[T] {
+	o, ok := other.(*bitSet[T])
+	if !ok {
+		return intersect[T](&bitSet[T]{}, s, other)
+	}
+
+	n := len(s.words)
+	if len(o.words) < n {
+		n = len(o.words)
+	}
+	intersection := &bitSet[T]{words: make([]uint64, n)}
+	for i := range intersection.words {
+		intersection.words[i] = s.words[i] & o.words[i]
+	}
+	intersection.trim()
+	intersection.count()
+	return intersection
+}
+
+func (s *bitSet[T]) IsProperSubset(other Set[T]) bool {
+	return s.IsSubset(other) && !s.Equal(other)
+}
+
+func (s *bitSet[T]) IsProperSuperset(other Set[T]) bool {
+	return s.IsSuperset(other) && !s.Equal(other)
+}
+
+func (s *bitSet[T]) IsSubset(other Set[T]) bool {
+	o, ok := other.(*bitSet[T])
+	if !ok {
+		return isSubset[T](s, other)
+	}
+
+	if s.size > o.size || len(s.words) > len(o.words) {
+		return false
+	}
+	for i, w := range s.words {
+		if w&^o.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *bitSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s *bitSet[T]) Iter() <-chan T {
+	return iterChan[T](s)
+}
+
+func (s *bitSet[T]) Iterator() *Iterator[T] {
+	return iterator[T](s)
+}
+
+func (s *bitSet[T]) Pull(ctx context.Context) *PullIterator[T] {
+	return newPullIterator(ctx, s.ToSlice())
+}
+
+func (s *bitSet[T]) All() func(yield func(T) bool) {
+	return all[T](s)
+}
+
+// Pop removes and returns the smallest element of the set.
+func (s *bitSet[T]) Pop() (v T, ok bool) {
+	s.Each(func(elem T) bool {
+		v, ok = elem, true
+		return true
+	})
+	if ok {
+		s.Remove(v)
+	}
+	return
+}
+
+func (s *bitSet[T]) Remove(v T) {
+	if !s.has(v) {
+		return
+	}
+	word, mask, _ := s.position(v)
+	s.words[word] &^= mask
+	s.size--
+	s.trim()
+}
+
+func (s *bitSet[T]) String() string {
+	return toString[T](s)
+}
+
+func (s *bitSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	o, ok := other.(*bitSet[T])
+	if !ok {
+		return symmetricDifference[T](&bitSet[T]{}, s, other)
+	}
+
+	long, short := s.words, o.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	diff := &bitSet[T]{words: append([]uint64(nil), long...)}
+	for i, w := range short {
+		diff.words[i] ^= w
+	}
+	diff.trim()
+	diff.count()
+	return diff
+}
+
+func (s *bitSet[T]) ToSlice() []T {
+	return toSlice[T](s)
+}
+
+// AddWith is Add, as Equal elements of a bit set are identical.
+func (s *bitSet[T]) AddWith(v T, _ MergeFunc[T]) bool {
+	return s.Add(v)
+}
+
+func (s *bitSet[T]) Replace(v T) (T, bool) {
+	return v, !s.Add(v)
+}
+
+// UnionWith is Union, as Equal elements of bit sets are identical.
+func (s *bitSet[T]) UnionWith(other Set[T], _ MergeFunc[T]) Set[T] {
+	return s.Union(other)
+}
+
+func (s *bitSet[T]) Union(other Set[T]) Set[T] {
+	o, ok := other.(*bitSet[T])
+	if !ok {
+		return addAll(s.Clone(), other)
+	}
+
+	long, short := s.words, o.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	union := &bitSet[T]{words: append([]uint64(nil), long...)}
+	for i, w := range short {
+		union.words[i] |= w
+	}
+	union.count()
+	return union
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+// in ascending order.
+func (s *bitSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T](s)
+}
+
+// UnmarshalJSON recreates a set from a JSON array, it only decodes
+// primitive types. Numbers are decoded as json.Number.
+func (s *bitSet[T]) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON[T](s, b)
+}
+
+// MarshalText creates a comma separated list of the elements of the set.
+func (s *bitSet[T]) MarshalText() ([]byte, error) {
+	return marshalText[T](s)
+}
+
+// UnmarshalText recreates a set from a comma separated list.
+func (s *bitSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText[T](s, text)
+}
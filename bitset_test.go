@@ -0,0 +1,131 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Assert concrete type:bitSet adheres to Set interface.
+var _ Set[Int] = (*bitSet[Int])(nil)
+
+func Test_BitSet(t *testing.T) {
+	runSafeUnsafe(t, NewBitSet[Int], NewThreadUnsafeBitSet[Int], func(t *testing.T, newSet func(...Int) Set[Int]) {
+		r := require.New(t)
+		s := newSet(130, 3, 64, 0, 3)
+
+		r.Equal(4, s.Cardinality())
+		r.True(s.Contains(0, 3, 64, 130))
+		r.False(s.Contains(1))
+		r.False(s.Contains(-1))
+		r.False(s.Contains(1000))
+		r.Equal([]Int{0, 3, 64, 130}, s.ToSlice())
+		r.Equal("Set{0, 3, 64, 130}", s.String())
+
+		r.False(s.Add(64))
+		r.True(s.Add(65))
+		s.Remove(130)
+		s.Remove(-5)
+		s.Remove(5000)
+		r.Equal([]Int{0, 3, 64, 65}, s.ToSlice())
+		r.True(s.Equal(newSet(65, 64, 3, 0)))
+
+		v, ok := s.Pop()
+		r.True(ok)
+		r.Equal(Int(0), v)
+		r.Equal(3, s.Cardinality())
+
+		b, err := json.Marshal(s)
+		r.NoError(err)
+		r.Equal(`[3,64,65]`, string(b))
+		d := newSet()
+		r.NoError(json.Unmarshal(b, d))
+		r.True(d.Equal(s))
+
+		s.Clear()
+		r.Zero(s.Cardinality())
+		_, ok = s.Pop()
+		r.False(ok)
+
+		r.Panics(func() { s.Add(-1) })
+		r.Panics(func() { s.Add(MaxBitSetElement + 1) })
+		r.Panics(func() { s.Add(1 << 62) })
+		r.True(s.Add(MaxBitSetElement))
+		r.False(s.Contains(1 << 62))
+		s.Remove(1 << 62)
+		r.Equal(1, s.Cardinality())
+	})
+}
+
+func Test_BitSetDecodeInvalid(t *testing.T) {
+	runSafeUnsafe(t, NewBitSet[Int], NewThreadUnsafeBitSet[Int], func(t *testing.T, newSet func(...Int) Set[Int]) {
+		r := require.New(t)
+		s := newSet(1, 2)
+
+		r.ErrorIs(json.Unmarshal([]byte("[3,-1]"), s), ErrInvalidElement)
+		r.ErrorIs(s.UnmarshalJSON([]byte("[99999999]")), ErrCapacityExceeded)
+		r.ErrorIs(s.UnmarshalText([]byte("3,-5")), ErrInvalidElement)
+		r.ErrorIs(NewFlag(s).Set("3,-5"), ErrInvalidElement)
+		r.ErrorIs(ArrayColumn[Int]{Set: s}.Scan("{3,99999999}"), ErrCapacityExceeded)
+		r.Equal([]Int{1, 2}, s.ToSlice(), "failed decoding leaves the set unchanged")
+	})
+}
+
+func Test_BitSetAlgebra(t *testing.T) {
+	for name, newOther := range map[string]func(...Int) Set[Int]{
+		"bitset":    NewThreadUnsafeBitSet[Int],
+		"safe":      NewBitSet[Int],
+		"threadset": NewThreadUnsafeSet[Int],
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			a := NewThreadUnsafeBitSet[Int](1, 2, 3, 200)
+			b := newOther(2, 3, 4, 500)
+
+			assertEqual(a.Union(b), NewThreadUnsafeBitSet[Int](1, 2, 3, 4, 200, 500), r)
+			assertEqual(a.Intersect(b), NewThreadUnsafeBitSet[Int](2, 3), r)
+			assertEqual(a.Difference(b), NewThreadUnsafeBitSet[Int](1, 200), r)
+			assertEqual(a.SymmetricDifference(b), NewThreadUnsafeBitSet[Int](1, 4, 200, 500), r)
+			r.Equal(2, a.Intersect(b).Cardinality())
+			r.Equal(4, a.SymmetricDifference(b).Cardinality())
+
+			r.True(newOther(2, 3).IsSubset(a))
+			r.True(NewThreadUnsafeBitSet[Int](2, 3).IsProperSubset(b))
+			r.False(a.IsSubset(b))
+			r.False(a.Equal(b))
+			r.True(a.Equal(newOther(200, 3, 2, 1)))
+
+			// Removing the largest element must not leave a set that
+			// compares unequal because of trailing empty words.
+			c := NewThreadUnsafeBitSet[Int](1, 2, 3, 200, 1000)
+			c.Remove(1000)
+			r.True(a.Equal(c))
+		})
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CanonicalSet wraps a Set so that it is always serialised canonically:
+// its elements are ordered by Key(), hence identical sets produce identical
+// bytes regardless of implementation and insertion order. This makes the
+// output suitable for golden files, content hashing or ETags.
+//
+// All other operations are those of the wrapped Set.
+type CanonicalSet[T EqualKeyer] struct {
+	Set[T]
+}
+
+// Canonical returns s wrapped into a CanonicalSet.
+func Canonical[T EqualKeyer](s Set[T]) CanonicalSet[T] {
+	return CanonicalSet[T]{Set: s}
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+// ordered by Key().
+func (c CanonicalSet[T]) MarshalJSON() ([]byte, error) {
+	return MarshalCanonicalJSON(c.Set)
+}
+
+// MarshalBinary encodes the set in the canonical binary form described at
+// MarshalCanonicalBinary.
+func (c CanonicalSet[T]) MarshalBinary() ([]byte, error) {
+	return MarshalCanonicalBinary(c.Set)
+}
+
+// UnmarshalBinary replaces the elements of the set with those encoded in the
+// binary form described at MarshalCanonicalBinary.
+func (c CanonicalSet[T]) UnmarshalBinary(b []byte) error {
+	return UnmarshalCanonicalBinary(b, c.Set)
+}
+
+// MarshalText creates a comma separated list of the elements of the set,
+// ordered by Key().
+func (c CanonicalSet[T]) MarshalText() ([]byte, error) {
+	f := defaultTextFormat[T]()
+	elems, err := canonicalElements(c.Set, func(elem T) ([]byte, error) {
+		text, err := f.format(elem)
+		return []byte(text), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]string, len(elems))
+	for i, elem := range elems {
+		items[i] = string(elem.enc)
+	}
+	return f.join(items)
+}
+
+// String returns the string representation of the set, listing its
+// elements ordered by Key().
+func (c CanonicalSet[T]) String() string {
+	elems, err := canonicalElements(c.Set, func(elem T) ([]byte, error) {
+		return []byte(fmt.Sprintf("%v", elem)), nil
+	})
+	if err != nil {
+		return c.Set.String()
+	}
+
+	items := make([]string, len(elems))
+	for i, elem := range elems {
+		items[i] = string(elem.enc)
+	}
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+// canonicalElement is an element of a set along with its encoding.
+type canonicalElement struct {
+	key string
+	enc []byte
+}
+
+// canonicalElements encodes the elements of s with encode and returns them
+// ordered by Key(). Elements with colliding keys are ordered by their
+// encoding, so that the order is fully deterministic.
+func canonicalElements[T EqualKeyer](s Set[T], encode func(T) ([]byte, error)) ([]canonicalElement, error) {
+	elems := make([]canonicalElement, 0, s.Cardinality())
+
+	var err error
+	s.Each(func(elem T) bool {
+		var enc []byte
+		enc, err = encode(elem)
+		if err != nil {
+			return true
+		}
+		elems = append(elems, canonicalElement{key: elem.Key(), enc: enc})
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(elems, func(i, j int) bool {
+		if elems[i].key != elems[j].key {
+			return elems[i].key < elems[j].key
+		}
+		return bytes.Compare(elems[i].enc, elems[j].enc) < 0
+	})
+	return elems, nil
+}
+
+// MarshalCanonicalJSON creates a JSON array from the set, it marshals all
+// elements ordered by Key(). Identical sets always produce identical bytes.
+func MarshalCanonicalJSON[T EqualKeyer](s Set[T]) ([]byte, error) {
+	elems, err := canonicalElements(s, func(elem T) ([]byte, error) {
+		return json.Marshal(elem)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, elem := range elems {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(elem.enc)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// MarshalCanonicalBinary encodes the set in a compact binary form ordered by
+// Key(). Identical sets always produce identical bytes.
+//
+// The encoding consists of the number of elements followed by the elements
+// themselves, each prefixed with its length. Numbers are written as
+// unsigned varints. Elements implementing encoding.BinaryMarshaler are
+// encoded with MarshalBinary, all others as JSON.
+func MarshalCanonicalBinary[T EqualKeyer](s Set[T]) ([]byte, error) {
+	elems, err := canonicalElements(s, func(elem T) ([]byte, error) {
+		if m, ok := any(elem).(encoding.BinaryMarshaler); ok {
+			return m.MarshalBinary()
+		}
+		return json.Marshal(elem)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := appendUvarint(nil, uint64(len(elems)))
+	for _, elem := range elems {
+		b = appendUvarint(b, uint64(len(elem.enc)))
+		b = append(b, elem.enc...)
+	}
+	return b, nil
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	return append(b, buf[:n]...)
+}
+
+var errCanonicalBinary = errors.New("mapset: malformed canonical binary encoding")
+
+// UnmarshalCanonicalBinary decodes the binary form created by
+// MarshalCanonicalBinary and replaces the elements of s with the decoded
+// elements, like UnmarshalJSON. If decoding fails, s is left unchanged.
+// Elements whose pointer type implements encoding.BinaryUnmarshaler are
+// decoded with UnmarshalBinary, all others as JSON.
+func UnmarshalCanonicalBinary[T EqualKeyer](b []byte, s Set[T]) error {
+	decoded := emptyClone(s)
+	if err := decodeCanonicalBinary(b, decoded); err != nil {
+		return err
+	}
+	replaceAll(s, decoded)
+	return nil
+}
+
+// decodeCanonicalBinary adds the elements encoded in b to s.
+func decodeCanonicalBinary[T EqualKeyer](b []byte, s Set[T]) error {
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return errCanonicalBinary
+	}
+	b = b[n:]
+
+	for ; count > 0; count-- {
+		size, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < size {
+			return errCanonicalBinary
+		}
+		enc := b[n : n+int(size)]
+		b = b[n+int(size):]
+
+		var v T
+		var err error
+		if u, ok := any(&v).(encoding.BinaryUnmarshaler); ok {
+			err = u.UnmarshalBinary(enc)
+		} else {
+			err = json.Unmarshal(enc, &v)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := addChecked(s, v); err != nil {
+			return err
+		}
+	}
+
+	if len(b) != 0 {
+		return errCanonicalBinary
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MarshalCanonicalJSON(t *testing.T) {
+	r := require.New(t)
+
+	expected := `["a","b","c","d"]`
+	for i := 0; i < 20; i++ {
+		b, err := MarshalCanonicalJSON(NewSet[String]("d", "b", "a", "c"))
+		r.NoError(err)
+		r.Equal(expected, string(b))
+
+		b, err = MarshalCanonicalJSON[String](NewThreadUnsafeOrderedSet[String]("c", "a", "d", "b"))
+		r.NoError(err)
+		r.Equal(expected, string(b))
+	}
+
+	b, err := MarshalCanonicalJSON(NewSet[String]())
+	r.NoError(err)
+	r.Equal(`[]`, string(b))
+
+	b, err = MarshalCanonicalJSON(NewSet[hashed](5, 2, 3, 0, 4, 1))
+	r.NoError(err)
+	r.Equal(`[0,3,1,4,2,5]`, string(b), "colliding keys should be ordered by their encoding")
+}
+
+func Test_CanonicalSet(t *testing.T) {
+	r := require.New(t)
+	s := Canonical(NewSet[String]("z", "x", "y"))
+
+	b, err := json.Marshal(s)
+	r.NoError(err)
+	r.Equal(`["x","y","z"]`, string(b))
+
+	b, err = json.Marshal(struct {
+		Tags CanonicalSet[String] `json:"tags"`
+	}{s})
+	r.NoError(err)
+	r.Equal(`{"tags":["x","y","z"]}`, string(b))
+
+	r.Equal("Set{x, y, z}", s.String())
+	r.True(s.Contains("x"), "other operations should be those of the wrapped set")
+}
+
+func Test_CanonicalBinary(t *testing.T) {
+	r := require.New(t)
+
+	a, err := MarshalCanonicalBinary(NewSet[String]("b", "a", "c"))
+	r.NoError(err)
+	b, err := Canonical(NewThreadUnsafeSet[String]("c", "b", "a")).MarshalBinary()
+	r.NoError(err)
+	r.Equal(a, b)
+	r.Equal([]byte("\x03\x03\"a\"\x03\"b\"\x03\"c\""), a)
+
+	d := NewSet[String]("old")
+	r.NoError(Canonical(d).UnmarshalBinary(a))
+	assertEqual(d, NewSet[String]("a", "b", "c"), r)
+
+	u := NewThreadUnsafeSet[String]("old")
+	r.NoError(UnmarshalCanonicalBinary(a, u))
+	assertEqual(u, NewSet[String]("a", "b", "c"), r)
+
+	for _, b := range [][]byte{a[:len(a)-1], append(a, 0), nil} {
+		d := NewSet[String]("old")
+		r.Error(UnmarshalCanonicalBinary(b, d))
+		assertEqual(d, NewSet[String]("old"), r)
+	}
+}
@@ -0,0 +1,532 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// marker is the comment which selects a type for generation.
+const marker = "//mapset:keyer"
+
+// escapeFunc is the name of the func generated for escaping separators.
+const escapeFunc = "mapsetEscapeKey"
+
+// config holds the settings of a run of the generator.
+type config struct {
+	dir     string
+	output  string
+	pointer bool
+	sep     string
+	types   []string
+}
+
+// keyerType is a type to generate methods for.
+type keyerType struct {
+	name    string
+	pointer bool
+	sep     byte
+	fields  []keyField
+}
+
+// keyField is a field which is part of the key of a keyerType.
+type keyField struct {
+	name string
+	// key is the expression of the key of the field, with %s standing
+	// for the receiver.
+	key string
+	// equal is the expression comparing the field of v to that of o.
+	equal string
+	// escape is whether key may contain the separator.
+	escape bool
+	// strconv is whether key uses the strconv package.
+	strconv bool
+}
+
+// generate returns the source of the file holding the methods of the types
+// selected by cfg.
+func generate(cfg config) ([]byte, error) {
+	p, err := load(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectTypes(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields of types which are generated in this run are Keyers, even
+	// though their methods do not exist yet.
+	generated := make(map[*types.TypeName]*keyerType)
+	var kts []*keyerType
+	for _, st := range selected {
+		kt := &keyerType{name: st.obj.Name(), pointer: st.pointer}
+		if kt.sep, err = parseSep(st.sep); err != nil {
+			return nil, fmt.Errorf("type %s: %v", kt.name, err)
+		}
+		generated[st.obj] = kt
+		kts = append(kts, kt)
+	}
+	for i, st := range selected {
+		if err := kts[i].resolveFields(st.obj, generated); err != nil {
+			return nil, err
+		}
+	}
+
+	return render(p, kts)
+}
+
+// pkg is a parsed and type checked package.
+type pkg struct {
+	files []*ast.File
+	types *types.Package
+}
+
+// load loads the package in cfg.dir. A previously generated output file is
+// left out, so that stale methods neither take part in type checking nor
+// break it. Type errors are tolerated, as the package may well use the
+// methods which are yet to be generated.
+func load(cfg config) (*pkg, error) {
+	bp, err := build.ImportDir(cfg.dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	output, err := filepath.Abs(cfg.output)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	p := &pkg{}
+	for _, name := range bp.GoFiles {
+		filename, err := filepath.Abs(filepath.Join(bp.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if filename == output {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.files = append(p.files, f)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	p.types, _ = conf.Check(bp.ImportPath, fset, p.files, nil)
+	return p, nil
+}
+
+// selectedType is a type selected for generation along with its options.
+type selectedType struct {
+	obj     *types.TypeName
+	pointer bool
+	sep     string
+}
+
+// selectTypes returns the types named in cfg.types, or the types marked
+// with the marker comment if there are none, in the order of cfg.types or
+// of their declaration respectively.
+func selectTypes(cfg config, p *pkg) ([]selectedType, error) {
+	marked := make(map[string]string)
+	var order []string
+	for _, file := range p.files {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				if opts, ok := markerOptions(doc); ok {
+					marked[ts.Name.Name] = opts
+					order = append(order, ts.Name.Name)
+				}
+			}
+		}
+	}
+
+	names := cfg.types
+	if len(names) == 0 {
+		names = order
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no types marked with %s found in %s", marker, cfg.dir)
+	}
+
+	var selected []selectedType
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		obj, ok := p.types.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in %s", name, cfg.dir)
+		}
+		st := selectedType{obj: obj, pointer: cfg.pointer, sep: cfg.sep}
+		for _, opt := range strings.Fields(marked[name]) {
+			switch {
+			case opt == "pointer":
+				st.pointer = true
+			case opt == "value":
+				st.pointer = false
+			case strings.HasPrefix(opt, "sep="):
+				st.sep = strings.TrimPrefix(opt, "sep=")
+			default:
+				return nil, fmt.Errorf("type %s: unknown option %q", name, opt)
+			}
+		}
+		selected = append(selected, st)
+	}
+	return selected, nil
+}
+
+// markerOptions returns the options following the marker comment in doc
+// and whether there is one.
+func markerOptions(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if c.Text == marker {
+			return "", true
+		}
+		if strings.HasPrefix(c.Text, marker+" ") {
+			return strings.TrimPrefix(c.Text, marker+" "), true
+		}
+	}
+	return "", false
+}
+
+// parseSep checks that sep is a single character which cannot appear in the
+// formatted numbers and booleans of keys, nor be confused with escaping.
+func parseSep(sep string) (byte, error) {
+	if len(sep) != 1 {
+		return 0, fmt.Errorf("separator %q is not a single character", sep)
+	}
+	c := sep[0]
+	if c <= ' ' || c >= 0x7f || c == '\\' || c == '-' ||
+		'0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+		return 0, fmt.Errorf("invalid separator %q", sep)
+	}
+	return c, nil
+}
+
+// resolveFields determines the key fields of kt, which is declared as obj.
+// Fields tagged with setkey form the key, or all fields if none is tagged.
+// A setkey tag of "-" excludes a field from the key.
+func (kt *keyerType) resolveFields(obj *types.TypeName, generated map[*types.TypeName]*keyerType) error {
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return fmt.Errorf("type %s: alias types are not supported", kt.name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return fmt.Errorf("type %s: generic types are not supported", kt.name)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return fmt.Errorf("type %s: not a struct type", kt.name)
+	}
+
+	var tagged, untagged []*types.Var
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if field.Name() == "_" {
+			continue
+		}
+		tag, ok := reflect.StructTag(st.Tag(i)).Lookup("setkey")
+		switch {
+		case tag == "-":
+		case ok:
+			tagged = append(tagged, field)
+		default:
+			untagged = append(untagged, field)
+		}
+	}
+	fields := tagged
+	if len(fields) == 0 {
+		fields = untagged
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("type %s: no key fields", kt.name)
+	}
+
+	for _, field := range fields {
+		kf, err := newKeyField(field, generated)
+		if err != nil {
+			return fmt.Errorf("type %s: %v", kt.name, err)
+		}
+		kt.fields = append(kt.fields, kf)
+	}
+	return nil
+}
+
+// newKeyField returns the key expressions of field. Keyers contribute their
+// keys and are compared by their Equal methods, booleans and integers are
+// formatted with strconv, and other comparable types with a String method
+// contribute the result of it.
+func newKeyField(field *types.Var, generated map[*types.TypeName]*keyerType) (keyField, error) {
+	name := field.Name()
+	kf := keyField{
+		name:  name,
+		equal: fmt.Sprintf("v.%s == o.%[1]s", name),
+	}
+
+	t := field.Type()
+	if keyer, addr := isKeyer(t, generated); keyer {
+		kf.key = "%s." + name + ".Key()"
+		kf.escape = true
+		if addr {
+			kf.equal = fmt.Sprintf("v.%s.Equal(&o.%[1]s)", name)
+		} else {
+			kf.equal = fmt.Sprintf("v.%s.Equal(o.%[1]s)", name)
+		}
+		return kf, nil
+	}
+
+	if basic, ok := t.Underlying().(*types.Basic); ok {
+		info := basic.Info()
+		switch {
+		case info&types.IsString != 0:
+			kf.key = convert(t, types.String, "%s."+name)
+			kf.escape = true
+			return kf, nil
+		case info&types.IsBoolean != 0:
+			kf.key = "strconv.FormatBool(" + convert(t, types.Bool, "%s."+name) + ")"
+		case info&types.IsUnsigned != 0:
+			kf.key = "strconv.FormatUint(" + convert(t, types.Uint64, "%s."+name) + ", 10)"
+		case types.Identical(t, types.Typ[types.Int]):
+			kf.key = "strconv.Itoa(%s." + name + ")"
+		case info&types.IsInteger != 0:
+			kf.key = "strconv.FormatInt(" + convert(t, types.Int64, "%s."+name) + ", 10)"
+		default:
+			return kf, fmt.Errorf("field %s: unsupported type %s, use a Keyer such as keyers.Float64 instead", name, t)
+		}
+		kf.strconv = true
+		return kf, nil
+	}
+
+	if types.Comparable(t) && hasMethod(t, "String", nil, []types.Type{types.Typ[types.String]}) {
+		kf.key = "%s." + name + ".String()"
+		kf.escape = true
+		return kf, nil
+	}
+
+	return kf, fmt.Errorf("field %s: unsupported type %s", name, t)
+}
+
+// convert returns expr converted to the basic type kind, unless t is
+// that type already.
+func convert(t types.Type, kind types.BasicKind, expr string) string {
+	if types.Identical(t, types.Typ[kind]) {
+		return expr
+	}
+	return types.Typ[kind].Name() + "(" + expr + ")"
+}
+
+// isKeyer reports whether t has the methods Equal(any) bool and
+// Key() string, and whether Equal must be passed the address of a value of
+// t, as it is declared with a pointer receiver. Pointers are only Keyers if
+// the methods are declared with pointer receivers, so that Equal expects
+// pointers.
+func isKeyer(t types.Type, generated map[*types.TypeName]*keyerType) (keyer, addr bool) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		value, pointer := keyerMethods(ptr.Elem(), generated)
+		return pointer && !value, false
+	}
+	value, pointer := keyerMethods(t, generated)
+	return value || pointer, !value && pointer
+}
+
+// keyerMethods reports whether the Keyer methods are in the method sets of
+// t and of *t respectively.
+func keyerMethods(t types.Type, generated map[*types.TypeName]*keyerType) (value, pointer bool) {
+	if named, ok := t.(*types.Named); ok {
+		if kt, ok := generated[named.Obj()]; ok {
+			return !kt.pointer, true
+		}
+	}
+	return hasKeyerMethods(t), hasKeyerMethods(types.NewPointer(t))
+}
+
+func hasKeyerMethods(t types.Type) bool {
+	anyType := types.Universe.Lookup("any").Type()
+	str := types.Typ[types.String]
+	boolean := types.Typ[types.Bool]
+	return hasMethod(t, "Equal", []types.Type{anyType}, []types.Type{boolean}) &&
+		hasMethod(t, "Key", nil, []types.Type{str})
+}
+
+// hasMethod reports whether the method set of t holds the method name with
+// the given parameter and result types.
+func hasMethod(t types.Type, name string, params, results []types.Type) bool {
+	sel := types.NewMethodSet(t).Lookup(nil, name)
+	if sel == nil {
+		return false
+	}
+	sig := sel.Type().(*types.Signature)
+	return !sig.Variadic() && tuplesMatch(sig.Params(), params) && tuplesMatch(sig.Results(), results)
+}
+
+func tuplesMatch(tuple *types.Tuple, want []types.Type) bool {
+	if tuple.Len() != len(want) {
+		return false
+	}
+	for i, t := range want {
+		if !types.Identical(tuple.At(i).Type(), t) {
+			return false
+		}
+	}
+	return true
+}
+
+// render returns the formatted source of the methods of kts in p.
+func render(p *pkg, kts []*keyerType) ([]byte, error) {
+	var needStrconv, needEscape bool
+	for _, kt := range kts {
+		for _, kf := range kt.fields {
+			needStrconv = needStrconv || kf.strconv
+			needEscape = needEscape || kf.escape && len(kt.fields) > 1
+		}
+	}
+	// The escape func may already be declared by another generated file.
+	declareEscape := needEscape && p.types.Scope().Lookup(escapeFunc) == nil
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by mapset-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", p.types.Name())
+	var imports []string
+	if needStrconv {
+		imports = append(imports, `"strconv"`)
+	}
+	if declareEscape {
+		imports = append(imports, `"strings"`)
+	}
+	switch len(imports) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&buf, "import %s\n", imports[0])
+	default:
+		fmt.Fprintf(&buf, "import (\n%s\n)\n", strings.Join(imports, "\n"))
+	}
+	for _, kt := range kts {
+		kt.render(&buf)
+	}
+	if declareEscape {
+		fmt.Fprintf(&buf, `
+// %[1]s escapes backslashes and sep in s with a backslash, so
+// that the values of composite keys cannot run into each other.
+func %[1]s(s string, sep byte) string {
+	if strings.IndexByte(s, '\\') < 0 && strings.IndexByte(s, sep) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || s[i] == sep {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+`, escapeFunc)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+func (kt *keyerType) render(buf *bytes.Buffer) {
+	recv := kt.name
+	if kt.pointer {
+		recv = "*" + kt.name
+	}
+
+	names := make([]string, len(kt.fields))
+	for i, kf := range kt.fields {
+		names[i] = kf.name
+	}
+	fieldList := strings.Join(names, ", ")
+	if len(names) > 1 {
+		fieldList = strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+
+	fmt.Fprintf(buf, "\n// Equal reports whether other is of type %s and agrees with v in %s.\n", recv, fieldList)
+	fmt.Fprintf(buf, "func (v %s) Equal(other any) bool {\n", recv)
+	fmt.Fprintf(buf, "o, ok := other.(%s)\n", recv)
+	fmt.Fprintf(buf, "if !ok {\nreturn false\n}\n")
+	if kt.pointer {
+		fmt.Fprintf(buf, "if v == nil || o == nil {\nreturn v == o\n}\n")
+	}
+	equals := make([]string, len(kt.fields))
+	for i, kf := range kt.fields {
+		equals[i] = kf.equal
+	}
+	fmt.Fprintf(buf, "return %s\n}\n", strings.Join(equals, " &&\n"))
+
+	fmt.Fprintf(buf, "\n// Key returns the key of v, made up of %s.\n", fieldList)
+	if kt.pointer {
+		fmt.Fprintf(buf, "// The key of nil is empty.\n")
+	}
+	fmt.Fprintf(buf, "func (v %s) Key() string {\n", recv)
+	if kt.pointer {
+		fmt.Fprintf(buf, "if v == nil {\nreturn \"\"\n}\n")
+	}
+	if len(kt.fields) == 1 {
+		fmt.Fprintf(buf, "return %s\n}\n", fmt.Sprintf(kt.fields[0].key, "v"))
+		return
+	}
+	parts := make([]string, len(kt.fields))
+	for i, kf := range kt.fields {
+		parts[i] = fmt.Sprintf(kf.key, "v")
+		if kf.escape {
+			parts[i] = fmt.Sprintf("%s(%s, %s)", escapeFunc, parts[i], strconv.QuoteRune(rune(kt.sep)))
+		}
+	}
+	fmt.Fprintf(buf, "return %s\n}\n", strings.Join(parts, " + "+strconv.Quote(string(kt.sep))+" +\n"))
+}
@@ -0,0 +1,131 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writePackage writes a package of the given source to a new directory and
+// returns the config for generating its methods.
+func writePackage(t *testing.T, src string) config {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "types.go"), []byte("package p\n\n"+src), 0o644)
+	require.NoError(t, err)
+	return config{dir: dir, output: filepath.Join(dir, "mapset_gen.go"), sep: "|"}
+}
+
+func Test_GenerateUpToDate(t *testing.T) {
+	r := require.New(t)
+
+	dir := filepath.Join("internal", "example")
+	output := filepath.Join(dir, "mapset_gen.go")
+	want, err := os.ReadFile(output)
+	r.NoError(err)
+
+	got, err := generate(config{dir: dir, output: output, sep: "|"})
+	r.NoError(err)
+	r.Equal(string(want), string(got), "run go generate in %s", dir)
+}
+
+func Test_GenerateTypeFlag(t *testing.T) {
+	r := require.New(t)
+
+	cfg := writePackage(t, `
+type A struct{ Name string }
+
+//mapset:keyer
+type B struct{ Name string }
+`)
+	cfg.types = []string{"A"}
+	cfg.pointer = true
+	src, err := generate(cfg)
+	r.NoError(err)
+	r.Contains(string(src), "func (v *A) Key() string")
+	r.NotContains(string(src), "func (v B)")
+	r.NotContains(string(src), "import", "single field keys need neither escaping nor strconv")
+}
+
+func Test_GenerateEscapeFuncDeclared(t *testing.T) {
+	r := require.New(t)
+
+	cfg := writePackage(t, `
+//mapset:keyer
+type A struct{ X, Y string }
+`)
+	other := "package p\n\nfunc mapsetEscapeKey(s string, sep byte) string { return s }\n"
+	r.NoError(os.WriteFile(filepath.Join(cfg.dir, "other_gen.go"), []byte(other), 0o644))
+
+	src, err := generate(cfg)
+	r.NoError(err)
+	r.Contains(string(src), "mapsetEscapeKey(v.X, '|')")
+	r.NotContains(string(src), "func mapsetEscapeKey")
+}
+
+func Test_GenerateStaleOutput(t *testing.T) {
+	r := require.New(t)
+
+	cfg := writePackage(t, `
+//mapset:keyer
+type A struct{ N int }
+
+var _ = A{}.Key()
+`)
+	stale := "package p\n\nfunc (v A) Key() string { return \"\" }\n"
+	r.NoError(os.WriteFile(cfg.output, []byte(stale), 0o644))
+
+	src, err := generate(cfg)
+	r.NoError(err)
+	r.Contains(string(src), "return strconv.Itoa(v.N)")
+}
+
+func Test_GenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"no types", "type A struct{ N int }\n", "no types marked"},
+		{"float", "//mapset:keyer\ntype A struct{ F float64 }\n", "field F: unsupported type float64"},
+		{"map", "//mapset:keyer\ntype A struct{ M map[string]int }\n", "field M: unsupported type"},
+		{"generic", "//mapset:keyer\ntype A[T any] struct{ V T }\n", "generic types are not supported"},
+		{"not a struct", "//mapset:keyer\ntype A int\n", "not a struct type"},
+		{"no key fields", "//mapset:keyer\ntype A struct{ N int `setkey:\"-\"` }\n", "no key fields"},
+		{"separator", "//mapset:keyer sep=x\ntype A struct{ N int }\n", `invalid separator "x"`},
+		{"long separator", "//mapset:keyer sep=::\ntype A struct{ N int }\n", "not a single character"},
+		{"option", "//mapset:keyer fast\ntype A struct{ N int }\n", `unknown option "fast"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(writePackage(t, tt.src))
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
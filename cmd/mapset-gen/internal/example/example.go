@@ -0,0 +1,80 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package example holds types whose Equal and Key methods are generated by
+// mapset-gen, it is used for testing the generator.
+package example
+
+import "fmt"
+
+//go:generate go run ../..
+
+// Employee is identified by its company and its ID, its name may change.
+//
+//mapset:keyer
+type Employee struct {
+	Company string `setkey:""`
+	ID      int64  `setkey:""`
+	Name    string
+}
+
+// Path is identified by all of its fields. The separator of its key may
+// appear in the fields, which are escaped therefore.
+//
+//mapset:keyer sep=/
+type Path struct {
+	Dir  string
+	Base string
+}
+
+// Node is used through pointers, it is identified by its name along with
+// its parent.
+//
+//mapset:keyer pointer
+type Node struct {
+	Parent *Node
+	Name   string
+	Weight uint `setkey:"-"`
+}
+
+// Version is a version number, it is not an EqualKeyer itself.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Release is identified by its product, its version and the employee who
+// released it.
+//
+//mapset:keyer
+type Release struct {
+	Product  string
+	Version  Version
+	Stable   bool
+	Releaser Employee
+}
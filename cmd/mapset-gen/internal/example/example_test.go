@@ -0,0 +1,111 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package example
+
+import (
+	"testing"
+
+	mapset "github.com/NectGmbH/golang-set/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CompositeKey(t *testing.T) {
+	r := require.New(t)
+
+	s := mapset.NewSet(
+		Employee{Company: "acme", ID: 1, Name: "Alice"},
+		Employee{Company: "acme", ID: 2, Name: "Bob"},
+		Employee{Company: "initech", ID: 1, Name: "Carol"},
+	)
+	r.Equal(3, s.Cardinality())
+
+	r.False(s.Add(Employee{Company: "acme", ID: 1, Name: "Alice Smith"}), "Name is not part of the key")
+	r.True(s.Contains(Employee{Company: "initech", ID: 1}))
+	r.False(s.Contains(Employee{Company: "initech", ID: 2}))
+	r.Equal("acme|1", Employee{Company: "acme", ID: 1}.Key())
+}
+
+func Test_SeparatorEscaping(t *testing.T) {
+	r := require.New(t)
+
+	a := Path{Dir: "a/b", Base: "c"}
+	b := Path{Dir: "a", Base: "b/c"}
+	r.NotEqual(a.Key(), b.Key())
+	r.Equal(`a\/b/c`, a.Key())
+
+	c := Path{Dir: `a\`, Base: "b"}
+	d := Path{Dir: "a", Base: `\b`}
+	r.NotEqual(c.Key(), d.Key())
+
+	s := mapset.NewSet(a, b, c, d)
+	r.Equal(4, s.Cardinality())
+	r.False(a.Equal(b))
+	r.True(a.Equal(Path{Dir: "a/b", Base: "c"}))
+}
+
+func Test_PointerReceivers(t *testing.T) {
+	r := require.New(t)
+
+	root := &Node{Name: "root"}
+	child := &Node{Parent: root, Name: "child", Weight: 1}
+	other := &Node{Parent: &Node{Name: "root"}, Name: "child", Weight: 2}
+
+	r.True(child.Equal(other))
+	r.Equal(child.Key(), other.Key())
+	r.False(child.Equal(root))
+	r.False(child.Equal(*other), "values are not pointers")
+
+	var nilNode *Node
+	r.True(nilNode.Equal(nilNode))
+	r.False(nilNode.Equal(root))
+	r.False(root.Equal(nilNode))
+	r.Equal("", nilNode.Key())
+
+	s := mapset.NewSet(root, child)
+	r.False(s.Add(other))
+	r.True(s.Contains(&Node{Name: "root"}))
+	r.Equal(2, s.Cardinality())
+}
+
+func Test_NestedKeyers(t *testing.T) {
+	r := require.New(t)
+
+	alice := Employee{Company: "acme", ID: 1, Name: "Alice"}
+	v1 := Release{Product: "set", Version: Version{1, 2, 3}, Stable: true, Releaser: alice}
+	r.Equal(`set|1.2.3|true|acme\|1`, v1.Key())
+
+	renamed := v1
+	renamed.Releaser.Name = "Alice Smith"
+	r.True(v1.Equal(renamed))
+
+	other := v1
+	other.Releaser.ID = 2
+	r.False(v1.Equal(other))
+	r.NotEqual(v1.Key(), other.Key())
+
+	s := mapset.NewSet(v1, renamed, other)
+	r.Equal(2, s.Cardinality())
+}
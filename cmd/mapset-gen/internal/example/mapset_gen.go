@@ -0,0 +1,99 @@
+// Code generated by mapset-gen; DO NOT EDIT.
+
+package example
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Equal reports whether other is of type Employee and agrees with v in Company and ID.
+func (v Employee) Equal(other any) bool {
+	o, ok := other.(Employee)
+	if !ok {
+		return false
+	}
+	return v.Company == o.Company &&
+		v.ID == o.ID
+}
+
+// Key returns the key of v, made up of Company and ID.
+func (v Employee) Key() string {
+	return mapsetEscapeKey(v.Company, '|') + "|" +
+		strconv.FormatInt(v.ID, 10)
+}
+
+// Equal reports whether other is of type Path and agrees with v in Dir and Base.
+func (v Path) Equal(other any) bool {
+	o, ok := other.(Path)
+	if !ok {
+		return false
+	}
+	return v.Dir == o.Dir &&
+		v.Base == o.Base
+}
+
+// Key returns the key of v, made up of Dir and Base.
+func (v Path) Key() string {
+	return mapsetEscapeKey(v.Dir, '/') + "/" +
+		mapsetEscapeKey(v.Base, '/')
+}
+
+// Equal reports whether other is of type *Node and agrees with v in Parent and Name.
+func (v *Node) Equal(other any) bool {
+	o, ok := other.(*Node)
+	if !ok {
+		return false
+	}
+	if v == nil || o == nil {
+		return v == o
+	}
+	return v.Parent.Equal(o.Parent) &&
+		v.Name == o.Name
+}
+
+// Key returns the key of v, made up of Parent and Name.
+// The key of nil is empty.
+func (v *Node) Key() string {
+	if v == nil {
+		return ""
+	}
+	return mapsetEscapeKey(v.Parent.Key(), '|') + "|" +
+		mapsetEscapeKey(v.Name, '|')
+}
+
+// Equal reports whether other is of type Release and agrees with v in Product, Version, Stable and Releaser.
+func (v Release) Equal(other any) bool {
+	o, ok := other.(Release)
+	if !ok {
+		return false
+	}
+	return v.Product == o.Product &&
+		v.Version == o.Version &&
+		v.Stable == o.Stable &&
+		v.Releaser.Equal(o.Releaser)
+}
+
+// Key returns the key of v, made up of Product, Version, Stable and Releaser.
+func (v Release) Key() string {
+	return mapsetEscapeKey(v.Product, '|') + "|" +
+		mapsetEscapeKey(v.Version.String(), '|') + "|" +
+		strconv.FormatBool(v.Stable) + "|" +
+		mapsetEscapeKey(v.Releaser.Key(), '|')
+}
+
+// mapsetEscapeKey escapes backslashes and sep in s with a backslash, so
+// that the values of composite keys cannot run into each other.
+func mapsetEscapeKey(s string, sep byte) string {
+	if strings.IndexByte(s, '\\') < 0 && strings.IndexByte(s, sep) < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' || s[i] == sep {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Mapset-gen generates the Equal and Key methods which make struct types
+// implement mapset.EqualKeyer, so that they can be stored in sets created
+// by mapset.NewSet.
+//
+// Types are selected by the -type flag or by a marker comment on their
+// declaration:
+//
+//	//mapset:keyer
+//	type Employee struct {
+//		Company string `setkey:""`
+//		ID      int64  `setkey:""`
+//		Name    string
+//	}
+//
+// The key of an element is made up of its fields tagged with setkey, or of
+// all of its fields if none is tagged, in the order of their declaration.
+// Elements are Equal if all of these fields are equal. Fields can be of any
+// boolean, integer or string type, of any comparable type with a String
+// method, or of any type with Equal and Key methods, such as other types
+// generated by mapset-gen or the types of the keyers package. The values of
+// composite keys are separated by "|", which is escaped with a backslash
+// within the values.
+//
+// The marker comment takes options which override the flags for its type:
+//
+//	//mapset:keyer pointer sep=/
+//
+// generates pointer receivers and separates the values of keys by "/".
+//
+// Usage:
+//
+//	mapset-gen [flags] [directory]
+//
+// It is typically run by go generate:
+//
+//	//go:generate go run github.com/NectGmbH/golang-set/v3/cmd/mapset-gen
+//
+// The flags are:
+//
+//	-output file
+//		name of the generated file (default "mapset_gen.go" in the directory)
+//	-pointer
+//		generate methods with pointer receivers
+//	-sep string
+//		separator of the values of composite keys, a single character (default "|")
+//	-type names
+//		comma separated list of the types to generate methods for, instead
+//		of the types marked with //mapset:keyer
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		output  = flag.String("output", "", `name of the generated file (default "mapset_gen.go" in the directory)`)
+		pointer = flag.Bool("pointer", false, "generate methods with pointer receivers")
+		sep     = flag.String("sep", "|", "separator of the values of composite keys, a single character")
+		types   = flag.String("type", "", "comma separated list of the types to generate methods for, instead of the types marked with //mapset:keyer")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: mapset-gen [flags] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config{
+		dir:     dir,
+		output:  *output,
+		pointer: *pointer,
+		sep:     *sep,
+	}
+	if *types != "" {
+		cfg.types = strings.Split(*types, ",")
+	}
+	if cfg.output == "" {
+		cfg.output = filepath.Join(dir, "mapset_gen.go")
+	}
+
+	src, err := generate(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mapset-gen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(cfg.output, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "mapset-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// The functions in this file implement Set operations purely in terms of
+// the Set interface. Implementations use them whenever they cannot take
+// advantage of their own internals, e.g. because the other operand is of
+// a different implementation. Results are accumulated in dst, which the
+// caller passes as an empty set of its own implementation.
+
+// addAll adds all elements of src to dst and returns dst.
+func addAll[T any](dst, src Set[T]) Set[T] {
+	src.Each(func(elem T) bool {
+		dst.Add(elem)
+		return false
+	})
+	return dst
+}
+
+// emptier is implemented by sets which can create an empty set of their
+// own implementation without copying their elements.
+type emptier[T any] interface {
+	emptySet() Set[T]
+}
+
+// replacer is implemented by sets which can take over the elements of src
+// without copying them. replaceWith reports whether src is of the same
+// implementation, otherwise it leaves the set unchanged.
+type replacer[T any] interface {
+	replaceWith(src Set[T]) bool
+}
+
+// emptyClone returns an empty set of the same implementation as s.
+func emptyClone[T any](s Set[T]) Set[T] {
+	if e, ok := s.(emptier[T]); ok {
+		return e.emptySet()
+	}
+	c := s.Clone()
+	c.Clear()
+	return c
+}
+
+// replaceAll replaces the elements of s with those of src, a set of the same
+// implementation which must not be used afterwards. Decoders build src
+// before calling it, so that s is left untouched if decoding fails. The
+// elements of src are taken over rather than copied where possible. If s is
+// thread-safe, its elements are replaced under a single lock.
+func replaceAll[T any](s, src Set[T]) {
+	l, ok := s.(lockedSet[T])
+	if !ok {
+		if r, ok := s.(replacer[T]); ok && r.replaceWith(src) {
+			return
+		}
+		s.Clear()
+		addAll(s, src)
+		return
+	}
+
+	if sl, ok := src.(lockedSet[T]); ok {
+		src = sl.locked().uss
+	}
+	ts := l.locked()
+	ts.lock()
+	ts.uss = src
+	ts.Unlock()
+}
+
+func difference[T any](dst, s, other Set[T]) Set[T] {
+	s.Each(func(elem T) bool {
+		if !other.Contains(elem) {
+			dst.Add(elem)
+		}
+		return false
+	})
+	return dst
+}
+
+func intersect[T any](dst, s, other Set[T]) Set[T] {
+	// loop over smaller set
+	small, large := s, other
+	if s.Cardinality() >= other.Cardinality() {
+		small, large = other, s
+	}
+	small.Each(func(elem T) bool {
+		if large.Contains(elem) {
+			dst.Add(elem)
+		}
+		return false
+	})
+	return dst
+}
+
+func symmetricDifference[T any](dst, s, other Set[T]) Set[T] {
+	difference(dst, s, other)
+	return difference(dst, other, s)
+}
+
+func isSubset[T any](s, other Set[T]) bool {
+	if s.Cardinality() > other.Cardinality() {
+		return false
+	}
+	subset := true
+	s.Each(func(elem T) bool {
+		subset = other.Contains(elem)
+		return !subset
+	})
+	return subset
+}
+
+func equal[T any](s, other Set[T]) bool {
+	return s.Cardinality() == other.Cardinality() && isSubset(s, other)
+}
+
+func iterChan[T any](s Set[T]) <-chan T {
+	ch := make(chan T)
+	go func() {
+		s.Each(func(elem T) bool {
+			ch <- elem
+			return false
+		})
+		close(ch)
+	}()
+
+	return ch
+}
+
+func iterator[T any](s Set[T]) *Iterator[T] {
+	iterator, ch, stopCh := newIterator[T]()
+
+	go func() {
+		s.Each(func(elem T) bool {
+			select {
+			case <-stopCh:
+				return true
+			case ch <- elem:
+				return false
+			}
+		})
+		close(ch)
+	}()
+
+	return iterator
+}
+
+func all[T any](s Set[T]) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		s.Each(func(elem T) bool {
+			return !yield(elem)
+		})
+	}
+}
+
+func toSlice[T any](s Set[T]) []T {
+	elems := make([]T, 0, s.Cardinality())
+	s.Each(func(elem T) bool {
+		elems = append(elems, elem)
+		return false
+	})
+
+	return elems
+}
+
+func toString[T any](s Set[T]) string {
+	items := make([]string, 0, s.Cardinality())
+
+	s.Each(func(elem T) bool {
+		items = append(items, fmt.Sprintf("%v", elem))
+		return false
+	})
+	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+}
+
+// marshalJSON creates a JSON array from the set, it marshals all elements
+func marshalJSON[T any](s Set[T]) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := EncodeJSON(&buf, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalJSON replaces the elements of the set with those of a JSON
+// array, it only decodes primitive types. Numbers are decoded as
+// json.Number.
+func unmarshalJSON[T any](s Set[T], b []byte) error {
+	return DecodeJSON(bytes.NewReader(b), s)
+}
@@ -0,0 +1,218 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import "context"
+
+// NewComparableSet creates and returns a new set with the given elements,
+// which may be of any comparable type. Elements are compared with ==, no
+// EqualKeyer implementation is needed. Operations on the resulting set are
+// thread-safe.
+func NewComparableSet[T comparable](vals ...T) Set[T] {
+	s := NewThreadUnsafeComparableSet(vals...)
+	return newThreadSafeSet(s)
+}
+
+// NewThreadUnsafeComparableSet creates and returns a new set with the given
+// elements, which may be of any comparable type. Elements are compared with
+// ==, no EqualKeyer implementation is needed. Operations on the resulting
+// set are not thread-safe.
+func NewThreadUnsafeComparableSet[T comparable](vals ...T) Set[T] {
+	s := make(comparableSet[T], len(vals))
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return &s
+}
+
+// comparableSet stores its elements directly as the keys of a map.
+type comparableSet[T comparable] map[T]struct{}
+
+// Assert concrete type:comparableSet adheres to Set interface.
+var _ Set[string] = (*comparableSet[string])(nil)
+
+func (s *comparableSet[T]) empty() *comparableSet[T] {
+	e := make(comparableSet[T])
+	return &e
+}
+
+func (s *comparableSet[T]) emptySet() Set[T] {
+	return s.empty()
+}
+
+func (s *comparableSet[T]) replaceWith(src Set[T]) bool {
+	o, ok := src.(*comparableSet[T])
+	if ok {
+		*s = *o
+	}
+	return ok
+}
+
+func (s *comparableSet[T]) Add(v T) bool {
+	prevLen := len(*s)
+	(*s)[v] = struct{}{}
+	return prevLen != len(*s)
+}
+
+// AddWith is Add, as Equal elements of a comparable set are identical.
+func (s *comparableSet[T]) AddWith(v T, _ MergeFunc[T]) bool {
+	return s.Add(v)
+}
+
+func (s *comparableSet[T]) Replace(v T) (T, bool) {
+	return v, !s.Add(v)
+}
+
+func (s *comparableSet[T]) Cardinality() int {
+	return len(*s)
+}
+
+func (s *comparableSet[T]) Clear() {
+	*s = make(comparableSet[T])
+}
+
+func (s *comparableSet[T]) Clone() Set[T] {
+	clonedSet := make(comparableSet[T], len(*s))
+	for elem := range *s {
+		clonedSet[elem] = struct{}{}
+	}
+	return &clonedSet
+}
+
+func (s *comparableSet[T]) Contains(v ...T) bool {
+	for _, val := range v {
+		if _, ok := (*s)[val]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *comparableSet[T]) Difference(other Set[T]) Set[T] {
+	return difference[T](s.empty(), s, other)
+}
+
+func (s *comparableSet[T]) Each(cb func(T) bool) {
+	for elem := range *s {
+		if cb(elem) {
+			break
+		}
+	}
+}
+
+func (s *comparableSet[T]) Equal(other Set[T]) bool {
+	return equal[T](s, other)
+}
+
+func (s *comparableSet[T]) Intersect(other Set[T]) Set[T] {
+	return intersect[T](s.empty(), s, other)
+}
+
+func (s *comparableSet[T]) IsProperSubset(other Set[T]) bool {
+	return s.IsSubset(other) && !s.Equal(other)
+}
+
+func (s *comparableSet[T]) IsProperSuperset(other Set[T]) bool {
+	return s.IsSuperset(other) && !s.Equal(other)
+}
+
+func (s *comparableSet[T]) IsSubset(other Set[T]) bool {
+	return isSubset[T](s, other)
+}
+
+func (s *comparableSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s *comparableSet[T]) Iter() <-chan T {
+	return iterChan[T](s)
+}
+
+func (s *comparableSet[T]) Iterator() *Iterator[T] {
+	return iterator[T](s)
+}
+
+func (s *comparableSet[T]) Pull(ctx context.Context) *PullIterator[T] {
+	return newPullIterator(ctx, s.ToSlice())
+}
+
+func (s *comparableSet[T]) All() func(yield func(T) bool) {
+	return all[T](s)
+}
+
+func (s *comparableSet[T]) Pop() (v T, ok bool) {
+	for item := range *s {
+		delete(*s, item)
+		return item, true
+	}
+	return
+}
+
+func (s *comparableSet[T]) Remove(v T) {
+	delete(*s, v)
+}
+
+func (s *comparableSet[T]) String() string {
+	return toString[T](s)
+}
+
+func (s *comparableSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	return symmetricDifference[T](s.empty(), s, other)
+}
+
+func (s *comparableSet[T]) ToSlice() []T {
+	return toSlice[T](s)
+}
+
+func (s *comparableSet[T]) Union(other Set[T]) Set[T] {
+	return addAll(s.Clone(), other)
+}
+
+// UnionWith is Union, as Equal elements of comparable sets are identical.
+func (s *comparableSet[T]) UnionWith(other Set[T], _ MergeFunc[T]) Set[T] {
+	return s.Union(other)
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+func (s *comparableSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T](s)
+}
+
+// UnmarshalJSON recreates a set from a JSON array, it only decodes
+// primitive types. Numbers are decoded as json.Number.
+func (s *comparableSet[T]) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON[T](s, b)
+}
+
+// MarshalText creates a comma separated list of the elements of the set.
+func (s *comparableSet[T]) MarshalText() ([]byte, error) {
+	return marshalText[T](s)
+}
+
+// UnmarshalText recreates a set from a comma separated list.
+func (s *comparableSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText[T](s, text)
+}
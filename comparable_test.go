@@ -0,0 +1,88 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	x, y int
+}
+
+func Test_ComparableSet(t *testing.T) {
+	runSafeUnsafe(t, NewComparableSet[string], NewThreadUnsafeComparableSet[string], func(t *testing.T, newSet func(...string) Set[string]) {
+		r := require.New(t)
+		s := newSet("eu", "us", "eu")
+
+		r.Equal(2, s.Cardinality())
+		r.True(s.Contains("eu", "us"))
+		r.False(s.Contains("ap"))
+		r.True(s.Add("ap"))
+		r.False(s.Add("ap"))
+		s.Remove("eu")
+		r.True(s.Equal(newSet("us", "ap")))
+
+		o := newSet("us", "sa")
+		r.True(s.Union(o).Equal(newSet("us", "ap", "sa")))
+		r.True(s.Intersect(o).Equal(newSet("us")))
+		r.True(s.Difference(o).Equal(newSet("ap")))
+		r.True(s.SymmetricDifference(o).Equal(newSet("ap", "sa")))
+		r.True(newSet("us").IsProperSubset(s))
+		r.True(s.IsSuperset(newSet("ap")))
+
+		elems := s.ToSlice()
+		sort.Strings(elems)
+		r.Equal([]string{"ap", "us"}, elems)
+
+		b, err := json.Marshal(s)
+		r.NoError(err)
+		d := newSet()
+		r.NoError(json.Unmarshal(b, d))
+		r.True(d.Equal(s))
+
+		v, ok := s.Pop()
+		r.True(ok)
+		r.False(s.Contains(v))
+		s.Clear()
+		r.Zero(s.Cardinality())
+	})
+}
+
+func Test_ComparableSetStructs(t *testing.T) {
+	r := require.New(t)
+	s := NewComparableSet(point{1, 2}, point{3, 4}, point{1, 2})
+
+	r.Equal(2, s.Cardinality())
+	r.True(s.Contains(point{3, 4}))
+
+	ids := NewThreadUnsafeComparableSet[int64](1, 2, 3)
+	r.True(ids.IsSuperset(NewComparableSet[int64](1, 3)))
+}
@@ -0,0 +1,237 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrIncompatibleSet is returned when a set cannot take part in an
+	// operation, such as a nil Set.
+	ErrIncompatibleSet = errors.New("mapset: incompatible set")
+
+	// ErrNilElement is returned when a set panics on a nil element, such
+	// as when it calls the Key method of the element.
+	ErrNilElement = errors.New("mapset: nil element")
+
+	// ErrInvalidElement is returned when an element cannot be stored in
+	// a set, such as a negative element of a bit set.
+	ErrInvalidElement = errors.New("mapset: invalid element")
+
+	// ErrKeyCollision is returned by TryAddUnique when an element shares
+	// its key with another element of the set that it is not Equal to.
+	ErrKeyCollision = errors.New("mapset: key collision")
+
+	// ErrCapacityExceeded is returned when an operation would exceed a
+	// configured maximum number of elements, or an element of a bit set
+	// exceeds MaxBitSetElement.
+	ErrCapacityExceeded = errors.New("mapset: capacity exceeded")
+)
+
+// checkedAdder is implemented by sets which may reject elements. checkedAdd
+// adds v like Add, but returns an error instead of panicking if v is
+// invalid for the set, or if unique is set and v collides with another
+// element of the set.
+type checkedAdder[T any] interface {
+	checkedAdd(v T, unique bool) (bool, error)
+}
+
+// addChecked adds v to s like s.Add, but through checkedAdd if s implements
+// checkedAdder, so that elements which s cannot store are returned as errors
+// instead of panicking. Decoders use it for elements of untrusted input.
+func addChecked[T any](s Set[T], v T) (bool, error) {
+	if c, ok := s.(checkedAdder[T]); ok {
+		return c.checkedAdd(v, false)
+	}
+	return s.Add(v), nil
+}
+
+// TryAdd adds v to s like s.Add, but returns an error instead of panicking
+// if s is nil or if v cannot be stored in s. If s panics on v, such as when
+// a key func dereferences a nil element, the panic is returned as an error
+// wrapping ErrNilElement if v is nil and ErrInvalidElement otherwise.
+func TryAdd[T any](s Set[T], v T) (bool, error) {
+	return tryAdd(s, v, false)
+}
+
+// TryAddUnique adds v to s like TryAdd, but fails with ErrKeyCollision if s
+// holds an element with the key of v which is not Equal to v, rather than
+// storing both. Sets whose elements have no keys never collide.
+func TryAddUnique[T any](s Set[T], v T) (bool, error) {
+	return tryAdd(s, v, true)
+}
+
+func tryAdd[T any](s Set[T], v T, unique bool) (added bool, err error) {
+	if err := checkSets(s); err != nil {
+		return false, err
+	}
+	defer recoverElements(&err, v)
+	if c, ok := s.(checkedAdder[T]); ok {
+		return c.checkedAdd(v, unique)
+	}
+	return s.Add(v), nil
+}
+
+// TryRemove removes v from s like s.Remove, but returns an error instead
+// of panicking if s is nil or if s panics on v, such as when its Key method
+// is called on a nil element.
+func TryRemove[T any](s Set[T], v T) (err error) {
+	if err := checkSets(s); err != nil {
+		return err
+	}
+	defer recoverElements(&err, v)
+	s.Remove(v)
+	return nil
+}
+
+// TryContains reports whether s contains all of vals like s.Contains, but
+// returns an error instead of panicking if s is nil or if s panics on any
+// of vals, such as when its Key method is called on a nil element.
+func TryContains[T any](s Set[T], vals ...T) (ok bool, err error) {
+	if err := checkSets(s); err != nil {
+		return false, err
+	}
+	defer recoverElements(&err, vals...)
+	return s.Contains(vals...), nil
+}
+
+// TryUnion returns a.Union(b), or ErrIncompatibleSet if either set is nil.
+// If a set panics on its elements, such as when their Equal method
+// dereferences nil, the panic is returned as an error wrapping
+// ErrInvalidElement.
+func TryUnion[T any](a, b Set[T]) (ret Set[T], err error) {
+	if err := checkSets(a, b); err != nil {
+		return nil, err
+	}
+	defer recoverElements[T](&err)
+	return a.Union(b), nil
+}
+
+// TryIntersect returns a.Intersect(b), or ErrIncompatibleSet if either set
+// is nil. Panics are returned like by TryUnion.
+func TryIntersect[T any](a, b Set[T]) (ret Set[T], err error) {
+	if err := checkSets(a, b); err != nil {
+		return nil, err
+	}
+	defer recoverElements[T](&err)
+	return a.Intersect(b), nil
+}
+
+// TryDifference returns a.Difference(b), or ErrIncompatibleSet if either
+// set is nil. Panics are returned like by TryUnion.
+func TryDifference[T any](a, b Set[T]) (ret Set[T], err error) {
+	if err := checkSets(a, b); err != nil {
+		return nil, err
+	}
+	defer recoverElements[T](&err)
+	return a.Difference(b), nil
+}
+
+// TrySymmetricDifference returns a.SymmetricDifference(b), or
+// ErrIncompatibleSet if either set is nil. Panics are returned like by
+// TryUnion.
+func TrySymmetricDifference[T any](a, b Set[T]) (ret Set[T], err error) {
+	if err := checkSets(a, b); err != nil {
+		return nil, err
+	}
+	defer recoverElements[T](&err)
+	return a.SymmetricDifference(b), nil
+}
+
+// TryEqual returns a.Equal(b), or ErrIncompatibleSet if either set is nil.
+// Panics are returned like by TryUnion.
+func TryEqual[T any](a, b Set[T]) (ok bool, err error) {
+	if err := checkSets(a, b); err != nil {
+		return false, err
+	}
+	defer recoverElements[T](&err)
+	return a.Equal(b), nil
+}
+
+// TryIsSubset returns a.IsSubset(b), or ErrIncompatibleSet if either set is
+// nil. Panics are returned like by TryUnion.
+func TryIsSubset[T any](a, b Set[T]) (ok bool, err error) {
+	if err := checkSets(a, b); err != nil {
+		return false, err
+	}
+	defer recoverElements[T](&err)
+	return a.IsSubset(b), nil
+}
+
+// TryIsSuperset returns a.IsSuperset(b), or ErrIncompatibleSet if either
+// set is nil. Panics are returned like by TryUnion.
+func TryIsSuperset[T any](a, b Set[T]) (ok bool, err error) {
+	if err := checkSets(a, b); err != nil {
+		return false, err
+	}
+	defer recoverElements[T](&err)
+	return a.IsSuperset(b), nil
+}
+
+// checkSets returns ErrIncompatibleSet if any of sets is nil, be it a nil
+// Set or a Set holding a nil pointer.
+func checkSets[T any](sets ...Set[T]) error {
+	for _, s := range sets {
+		if s == nil {
+			return fmt.Errorf("%w: nil Set", ErrIncompatibleSet)
+		}
+		if v := reflect.ValueOf(s); isNilValue(v) {
+			return fmt.Errorf("%w: nil %v", ErrIncompatibleSet, v.Type())
+		}
+	}
+	return nil
+}
+
+// recoverElements recovers from a panic of a set on elems and stores it in
+// *err, wrapping ErrNilElement if any of elems is nil and ErrInvalidElement
+// otherwise. It must be deferred.
+func recoverElements[T any](err *error, elems ...T) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	kind := ErrInvalidElement
+	for _, elem := range elems {
+		if any(elem) == nil || isNilValue(reflect.ValueOf(elem)) {
+			kind = ErrNilElement
+			break
+		}
+	}
+	*err = fmt.Errorf("%w of type %v: %v", kind, reflect.TypeOf((*T)(nil)).Elem(), r)
+}
+
+// isNilValue reports whether v holds a nil pointer, map, slice, func or
+// channel.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
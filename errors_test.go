@@ -0,0 +1,222 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TryAdd(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[*yourType]()
+	added, err := TryAdd(s, &yourType{name: "Bob"})
+	r.NoError(err)
+	r.True(added)
+
+	_, err = TryAdd(s, nil)
+	r.ErrorIs(err, ErrNilElement)
+	_, err = TryAdd[EqualKeyer](NewThreadUnsafeSet[EqualKeyer](), nil)
+	r.ErrorIs(err, ErrNilElement)
+
+	// Elements without a Key method may be nil.
+	added, err = TryAdd(NewComparableSet[*int](), nil)
+	r.NoError(err)
+	r.True(added)
+
+	// Key funcs panicking on nil elements are recovered from.
+	keyed := NewKeyedSet(func(p *int) string { return strconv.Itoa(*p) }, nil)
+	_, err = TryAdd(keyed, nil)
+	r.ErrorIs(err, ErrNilElement)
+	r.Equal(0, keyed.Cardinality())
+	r.NotPanics(func() { keyed.Add(new(int)) }, "the set is unlocked after recovering")
+	_, err = TryContains(keyed, nil)
+	r.ErrorIs(err, ErrNilElement)
+	r.ErrorIs(TryRemove(keyed, nil), ErrNilElement)
+	_, err = TryAdd(keyed, nil)
+	r.ErrorIs(err, ErrNilElement)
+
+	// Panics on elements which are not nil are invalid elements.
+	_, err = TryAdd(NewKeyedSet(func(int) string { panic("no key") }, nil), 1)
+	r.ErrorIs(err, ErrInvalidElement)
+
+	var nilSet Set[String]
+	_, err = TryAdd(nilSet, "a")
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryAdd[String]((*threadUnsafeSet[string, String])(nil), "a")
+	r.ErrorIs(err, ErrIncompatibleSet)
+
+	for _, b := range []Set[Int]{NewBitSet[Int](), NewThreadUnsafeBitSet[Int]()} {
+		_, err = TryAdd(b, -1)
+		r.ErrorIs(err, ErrInvalidElement)
+		_, err = TryAdd(b, 1<<62)
+		r.ErrorIs(err, ErrCapacityExceeded)
+		added, err = TryAdd(b, 1)
+		r.NoError(err)
+		r.True(added)
+		r.Panics(func() { b.Add(-1) })
+	}
+}
+
+func Test_TryAddUnique(t *testing.T) {
+	r := require.New(t)
+
+	for _, s := range []Set[hashed]{
+		NewSet[hashed](0, 1),
+		NewThreadUnsafeSet[hashed](0, 1),
+		NewOrderedSet[hashed](0, 1),
+		NewSortedSet[hashed](func(a, b hashed) bool { return a < b }, 0, 1),
+	} {
+		added, err := TryAddUnique(s, 2)
+		r.NoError(err)
+		r.True(added)
+
+		added, err = TryAddUnique(s, 0)
+		r.NoError(err, "Equal elements do not collide")
+		r.False(added)
+
+		_, err = TryAddUnique(s, 3)
+		r.ErrorIs(err, ErrKeyCollision)
+		r.False(s.Contains(3))
+
+		added, err = TryAdd(s, 3)
+		r.NoError(err, "TryAdd stores colliding elements side by side")
+		r.True(added)
+	}
+
+	added, err := TryAddUnique(NewComparableSet[int](1), 2)
+	r.NoError(err)
+	r.True(added)
+}
+
+func Test_TryOperations(t *testing.T) {
+	r := require.New(t)
+
+	a := NewSet[String]("a", "b")
+	b := NewThreadUnsafeSet[String]("b", "c")
+	var nilSet Set[String]
+
+	u, err := TryUnion(a, b)
+	r.NoError(err)
+	assertEqual(u, NewSet[String]("a", "b", "c"), r)
+	i, err := TryIntersect(a, b)
+	r.NoError(err)
+	assertEqual(i, NewSet[String]("b"), r)
+	d, err := TryDifference(a, b)
+	r.NoError(err)
+	assertEqual(d, NewSet[String]("a"), r)
+	sd, err := TrySymmetricDifference(a, b)
+	r.NoError(err)
+	assertEqual(sd, NewSet[String]("a", "c"), r)
+	eq, err := TryEqual(a, b)
+	r.NoError(err)
+	r.False(eq)
+	sub, err := TryIsSubset(NewSet[String]("a"), a)
+	r.NoError(err)
+	r.True(sub)
+	sup, err := TryIsSuperset(a, NewSet[String]("a"))
+	r.NoError(err)
+	r.True(sup)
+	ok, err := TryContains(a, "a", "b")
+	r.NoError(err)
+	r.True(ok)
+	r.NoError(TryRemove(a, "a"))
+	r.False(a.Contains("a"))
+
+	_, err = TryUnion(a, nilSet)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryIntersect(nilSet, a)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryDifference(a, nilSet)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TrySymmetricDifference(a, nilSet)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryEqual(a, nilSet)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryIsSubset(a, nilSet)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryIsSuperset(nilSet, a)
+	r.ErrorIs(err, ErrIncompatibleSet)
+	_, err = TryContains(nilSet, "a")
+	r.ErrorIs(err, ErrIncompatibleSet)
+	r.ErrorIs(TryRemove(nilSet, "a"), ErrIncompatibleSet)
+
+	p := NewSet[*yourType]()
+	_, err = TryContains(p, nil, &yourType{name: "a"})
+	r.ErrorIs(err, ErrNilElement)
+	r.ErrorIs(TryRemove(p, nil), ErrNilElement)
+}
+
+// nilField panics in Equal if either element has a nil v. All nilFields
+// share a key, so that sets compare them.
+type nilField struct {
+	v *int
+}
+
+func (n nilField) Equal(other any) bool {
+	o, ok := other.(nilField)
+	return ok && *n.v == *o.v
+}
+
+func (n nilField) Key() string {
+	return ""
+}
+
+func Test_TryOperationsPanics(t *testing.T) {
+	r := require.New(t)
+
+	a := NewSet(nilField{new(int)})
+	b := NewThreadUnsafeSet(nilField{})
+
+	_, err := TryUnion(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	_, err = TryIntersect(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	_, err = TryDifference(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	_, err = TrySymmetricDifference(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	_, err = TryEqual(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	_, err = TryIsSubset(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	_, err = TryIsSuperset(a, b)
+	r.ErrorIs(err, ErrInvalidElement)
+	r.Equal(1, a.Cardinality())
+}
+
+func Test_ErrCapacityExceeded(t *testing.T) {
+	r := require.New(t)
+
+	err := DecodeJSON(strings.NewReader(`["a", "b"]`), NewSet[String](), WithMaxElements(1))
+	r.ErrorIs(err, ErrCapacityExceeded)
+	r.False(errors.Is(err, ErrKeyCollision))
+}
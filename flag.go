@@ -0,0 +1,127 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import "fmt"
+
+var errFlagNilSet = fmt.Errorf("%w: Flag has no Set, create it with NewFlag", ErrIncompatibleSet)
+
+// Flag adapts a Set to the flag.Value and flag.Getter interfaces, so that a
+// set can be bound to a command-line flag with flag.Var:
+//
+//	regions := mapset.NewSet[mapset.String]()
+//	flag.Var(mapset.NewFlag(regions), "allowed-regions", "comma separated list of regions")
+//
+// Each occurrence of the flag adds the listed elements to the set. Flag
+// also implements encoding.TextMarshaler and encoding.TextUnmarshaler,
+// using its own format, so it can be handed to any library decoding
+// configuration through them. Flags must be created with NewFlag, the
+// methods of a Flag without a set return an error.
+type Flag[T any] struct {
+	// Separator delimits the elements, a comma if zero.
+	Separator rune
+
+	// NoQuotes disables quoting, elements are split at every
+	// separator. By default, elements can be enclosed in double quotes
+	// as in CSV, so that they may contain the separator.
+	NoQuotes bool
+
+	// Parse converts a single element to T. If Parse is nil, elements
+	// whose pointer type implements encoding.TextUnmarshaler are parsed
+	// with UnmarshalText, elements of an underlying string type are taken
+	// verbatim and all others are decoded as JSON.
+	Parse func(string) (T, error)
+
+	// Format converts a single element to text. If Format is nil,
+	// elements implementing encoding.TextMarshaler are formatted with
+	// MarshalText and all others as by fmt.Sprint.
+	Format func(T) (string, error)
+
+	set Set[T]
+}
+
+// NewFlag returns a Flag which adds the elements passed on the command
+// line to s.
+func NewFlag[T any](s Set[T]) *Flag[T] {
+	return &Flag[T]{set: s}
+}
+
+func (f *Flag[T]) textFormat() textFormat[T] {
+	format := defaultTextFormat[T]()
+	if f.Separator != 0 {
+		format.separator = f.Separator
+	}
+	format.noQuotes = f.NoQuotes
+	if f.Parse != nil {
+		format.parse = f.Parse
+	}
+	if f.Format != nil {
+		format.format = f.Format
+	}
+	return format
+}
+
+// String returns the elements of the set as a list in the format of f.
+func (f *Flag[T]) String() string {
+	if f == nil || f.set == nil {
+		return ""
+	}
+	b, err := f.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Set adds the elements of the list value to the set.
+func (f *Flag[T]) Set(value string) error {
+	if f.set == nil {
+		return errFlagNilSet
+	}
+	return f.textFormat().unmarshal(f.set, []byte(value))
+}
+
+// Get returns the underlying Set[T].
+func (f *Flag[T]) Get() any {
+	return f.set
+}
+
+// MarshalText returns the elements of the set as a list in the format of f.
+func (f *Flag[T]) MarshalText() ([]byte, error) {
+	if f.set == nil {
+		return nil, errFlagNilSet
+	}
+	return f.textFormat().marshal(f.set)
+}
+
+// UnmarshalText replaces the elements of the set with those of the list
+// text.
+func (f *Flag[T]) UnmarshalText(text []byte) error {
+	if f.set == nil {
+		return errFlagNilSet
+	}
+	return f.textFormat().replace(f.set, text)
+}
@@ -0,0 +1,95 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"flag"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ flag.Getter = NewFlag[String](nil)
+
+func Test_Flag(t *testing.T) {
+	r := require.New(t)
+
+	regions := NewOrderedSet[String]()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(NewFlag[String](regions), "allowed-regions", "regions")
+
+	r.NoError(fs.Parse([]string{"--allowed-regions=eu,us", "--allowed-regions", `"ap,south",eu`}))
+	r.Equal([]String{"eu", "us", "ap,south"}, regions.ToSlice())
+	r.Equal(`eu,us,"ap,south"`, fs.Lookup("allowed-regions").Value.String())
+	r.Equal(regions, fs.Lookup("allowed-regions").Value.(flag.Getter).Get())
+
+	r.Error(fs.Parse([]string{`--allowed-regions="eu`}))
+}
+
+func Test_FlagFormat(t *testing.T) {
+	r := require.New(t)
+
+	ports := NewOrderedSet[Int]()
+	f := NewFlag[Int](ports)
+	f.Separator = ';'
+	f.NoQuotes = true
+	f.Parse = func(s string) (Int, error) {
+		i, err := strconv.Atoi(s)
+		return Int(i), err
+	}
+	f.Format = func(i Int) (string, error) {
+		return ":" + strconv.Itoa(int(i)), nil
+	}
+
+	r.NoError(f.Set("80;443"))
+	r.Equal([]Int{80, 443}, ports.ToSlice())
+	r.Equal(":80;:443", f.String())
+	r.Error(f.Set("8080;x"))
+
+	r.NoError(f.UnmarshalText([]byte("22")))
+	r.Equal([]Int{22}, ports.ToSlice())
+	b, err := f.MarshalText()
+	r.NoError(err)
+	r.Equal(":22", string(b))
+
+	var zero *Flag[Int]
+	r.Equal("", zero.String())
+}
+
+func Test_FlagWithoutSet(t *testing.T) {
+	r := require.New(t)
+
+	for _, f := range []*Flag[String]{{Separator: ';'}, NewFlag[String](nil)} {
+		r.ErrorIs(f.Set("a"), ErrIncompatibleSet)
+		_, err := f.MarshalText()
+		r.ErrorIs(err, ErrIncompatibleSet)
+		r.ErrorIs(f.UnmarshalText([]byte("a")), ErrIncompatibleSet)
+		r.Empty(f.String())
+	}
+}
@@ -25,22 +25,26 @@ SOFTWARE.
 
 package mapset
 
+import (
+	"context"
+	"sync"
+)
+
 // Iterator defines an iterator over a Set, its C channel can be used to range over the Set's
 // elements.
-type Iterator[T EqualKeyer] struct {
+type Iterator[T any] struct {
 	C    <-chan T
 	stop chan struct{}
+	once sync.Once
 }
 
 // Stop stops the Iterator, no further elements will be received on C, C will be closed.
 func (i *Iterator[T]) Stop() {
 	// Allows for Stop() to be called multiple times
 	// (close() panics when called on already closed channel)
-	defer func() {
-		recover()
-	}()
-
-	close(i.stop)
+	i.once.Do(func() {
+		close(i.stop)
+	})
 
 	// Exhaust any remaining elements.
 	for range i.C {
@@ -48,7 +52,7 @@ func (i *Iterator[T]) Stop() {
 }
 
 // newIterator returns a new Iterator instance together with its item and stop channels.
-func newIterator[T EqualKeyer]() (*Iterator[T], chan<- T, <-chan struct{}) {
+func newIterator[T any]() (*Iterator[T], chan<- T, <-chan struct{}) {
 	itemChan := make(chan T)
 	stopChan := make(chan struct{})
 	return &Iterator[T]{
@@ -56,3 +60,68 @@ func newIterator[T EqualKeyer]() (*Iterator[T], chan<- T, <-chan struct{}) {
 		stop: stopChan,
 	}, itemChan, stopChan
 }
+
+// PullIterator iterates over a snapshot of a Set taken when it was
+// created. It neither spawns goroutines nor holds locks of the Set, so
+// it is safe to abandon at any time; Close merely releases the snapshot
+// early. A PullIterator must not be used by multiple goroutines at once.
+//
+//	it := s.Pull(ctx)
+//	defer it.Close()
+//	for it.Next() {
+//		use(it.Value())
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type PullIterator[T any] struct {
+	ctx   context.Context
+	elems []T
+	cur   T
+	err   error
+}
+
+// newPullIterator returns a PullIterator over elems bound to ctx.
+func newPullIterator[T any](ctx context.Context, elems []T) *PullIterator[T] {
+	return &PullIterator[T]{ctx: ctx, elems: elems}
+}
+
+// Next advances the iterator to the next element, which is then
+// available through Value. It returns false once all elements have been
+// visited, the iterator was closed or its context is done.
+func (i *PullIterator[T]) Next() bool {
+	if i.err != nil {
+		return false
+	}
+	if err := i.ctx.Err(); err != nil {
+		i.err = err
+		i.Close()
+		return false
+	}
+	if len(i.elems) == 0 {
+		i.Close()
+		return false
+	}
+
+	i.cur, i.elems = i.elems[0], i.elems[1:]
+	return true
+}
+
+// Value returns the current element, or the zero value if Next has not
+// been called yet or returned false.
+func (i *PullIterator[T]) Value() T {
+	return i.cur
+}
+
+// Err returns the context's error if the iteration stopped because its
+// context is done, nil otherwise.
+func (i *PullIterator[T]) Err() error {
+	return i.err
+}
+
+// Close stops the iteration and releases the snapshot. It can be called
+// multiple times.
+func (i *PullIterator[T]) Close() {
+	var zero T
+	i.elems, i.cur = nil, zero
+}
@@ -0,0 +1,209 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DecodeOption configures how sets are decoded from JSON.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	maxElements      int
+	merge            bool
+	rejectDuplicates bool
+}
+
+// WithMerge makes decoding add the elements of the JSON array to the
+// existing elements of the set. By default the set is cleared first, so
+// that it holds exactly the elements of the JSON array afterwards.
+func WithMerge() DecodeOption {
+	return func(o *decodeOptions) {
+		o.merge = true
+	}
+}
+
+// WithRejectDuplicates makes decoding fail when the JSON array holds
+// elements which are equal to each other. With WithMerge, elements which
+// are already in the set are not considered duplicates.
+func WithRejectDuplicates() DecodeOption {
+	return func(o *decodeOptions) {
+		o.rejectDuplicates = true
+	}
+}
+
+// WithMaxElements limits the number of elements of the JSON array to n.
+// Decoding fails as soon as the array turns out to hold more elements,
+// without reading the remainder of it. A limit of 0 or less means no limit.
+func WithMaxElements(n int) DecodeOption {
+	return func(o *decodeOptions) {
+		o.maxElements = n
+	}
+}
+
+// EncodeJSON writes the elements of s to w as a JSON array. Elements are
+// marshaled and written one at a time, so the encoding of the whole set is
+// never held in memory.
+func EncodeJSON[T any](w io.Writer, s Set[T]) error {
+	bw := bufio.NewWriter(w)
+
+	err := bw.WriteByte('[')
+	first := true
+	s.Each(func(elem T) bool {
+		var b []byte
+		b, err = json.Marshal(elem)
+		if err != nil {
+			return true
+		}
+
+		if !first {
+			if err = bw.WriteByte(','); err != nil {
+				return true
+			}
+		}
+		first = false
+		_, err = bw.Write(b)
+		return err != nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := bw.WriteByte(']'); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// DecodeJSON reads a JSON array from r and stores its elements in s,
+// replacing the previous elements of s unless WithMerge is given. Elements
+// are decoded one at a time, so the input is never held in memory as a
+// whole. Numbers are decoded as json.Number. A JSON null holds no elements.
+//
+// The elements are decoded into a new set, which only takes the place of
+// the contents of s once the whole array has been decoded. If decoding
+// fails, s is left unchanged. If s is thread-safe, its contents are
+// replaced under a single lock, but changes made to s while decoding are
+// lost. Use UnmarshalJSON to decode a JSON array with s locked throughout.
+func DecodeJSON[T any](r io.Reader, s Set[T], opts ...DecodeOption) error {
+	decoded, err := decodeJSON(r, s, newDecodeOptions(opts))
+	if err != nil {
+		return err
+	}
+	replaceAll(s, decoded)
+	return nil
+}
+
+func newDecodeOptions(opts []DecodeOption) decodeOptions {
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// decodeJSON decodes the JSON array read from r into a new set of the same
+// implementation as s, which starts out with the elements of s if merging.
+func decodeJSON[T any](r io.Reader, s Set[T], o decodeOptions) (Set[T], error) {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	var decoded Set[T]
+	if o.merge {
+		decoded = s.Clone()
+	} else {
+		decoded = emptyClone(s)
+	}
+	if tok == nil {
+		return decoded, nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("mapset: cannot decode JSON %v into a set, expected an array", tok)
+	}
+
+	// seen holds the decoded elements when duplicates are to be rejected
+	// but decoded cannot tell them apart from the previous elements of s.
+	var seen Set[T]
+	if o.merge && o.rejectDuplicates {
+		seen = emptyClone(s)
+	}
+
+	for n := 0; d.More(); n++ {
+		if o.maxElements > 0 && n >= o.maxElements {
+			return nil, fmt.Errorf("%w: JSON array holds more than %d elements", ErrCapacityExceeded, o.maxElements)
+		}
+
+		var v T
+		if err := d.Decode(&v); err != nil {
+			return nil, err
+		}
+		added, err := addChecked(decoded, v)
+		if err != nil {
+			return nil, err
+		}
+		if seen != nil {
+			added = seen.Add(v)
+		}
+		if !added && o.rejectDuplicates {
+			return nil, fmt.Errorf("mapset: JSON array holds duplicate element %v", v)
+		}
+	}
+
+	// Consume the closing bracket.
+	if _, err := d.Token(); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// UnmarshalJSON decodes the JSON array b into s like DecodeJSON. If s is
+// thread-safe, it is locked for the whole decoding. Use it to decode sets
+// with other than the default options of their UnmarshalJSON method.
+func UnmarshalJSON[T any](b []byte, s Set[T], opts ...DecodeOption) error {
+	l, ok := s.(lockedSet[T])
+	if !ok {
+		return DecodeJSON(bytes.NewReader(b), s, opts...)
+	}
+
+	ts := l.locked()
+	ts.lock()
+	defer ts.Unlock()
+	decoded, err := decodeJSON(bytes.NewReader(b), ts.uss, newDecodeOptions(opts))
+	if err != nil {
+		return err
+	}
+	ts.uss = decoded
+	return nil
+}
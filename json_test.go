@@ -0,0 +1,281 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func Test_EncodeJSON(t *testing.T) {
+	r := require.New(t)
+
+	var buf bytes.Buffer
+	r.NoError(EncodeJSON[String](&buf, NewOrderedSet[String]("a", "b", "c")))
+	r.Equal(`["a","b","c"]`, buf.String())
+
+	buf.Reset()
+	r.NoError(EncodeJSON(&buf, NewSet[String]()))
+	r.Equal(`[]`, buf.String())
+
+	s := NewThreadUnsafeSet[Int]()
+	for i := 0; i < 10000; i++ {
+		s.Add(Int(i))
+	}
+	r.Error(EncodeJSON(failingWriter{}, s))
+
+	buf.Reset()
+	r.NoError(EncodeJSON(&buf, s))
+	d := NewThreadUnsafeSet[Int]()
+	r.NoError(DecodeJSON(&buf, d))
+	assertEqual(s, d, r)
+}
+
+func Test_DecodeJSON(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]()
+	r.NoError(DecodeJSON(strings.NewReader(`["a", "b", "a"]`), s))
+	assertEqual(s, NewSet[String]("a", "b"), r)
+
+	s = NewSet[String]()
+	r.NoError(DecodeJSON(strings.NewReader(`null`), s))
+	r.Zero(s.Cardinality())
+
+	r.Error(DecodeJSON(strings.NewReader(`{"a": 1}`), NewSet[String]()))
+	r.Error(DecodeJSON(strings.NewReader(`["a", 1]`), NewSet[String]()))
+	r.Error(DecodeJSON(strings.NewReader(`["a", "b"`), NewSet[String]()))
+}
+
+func Test_DecodeJSONMaxElements(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]()
+	r.NoError(DecodeJSON(strings.NewReader(`["a", "b", "c"]`), s, WithMaxElements(3)))
+	r.Equal(3, s.Cardinality())
+
+	s = NewSet[String]("keep")
+	err := DecodeJSON(strings.NewReader(`["a", "b", "c", "d", {"not": "read"}]`), s, WithMaxElements(3))
+	r.ErrorIs(err, ErrCapacityExceeded)
+	assertEqual(s, NewSet[String]("keep"), r)
+
+	err = UnmarshalJSON([]byte(`["a", "b", "c"]`), s, WithMaxElements(2))
+	r.ErrorIs(err, ErrCapacityExceeded)
+	assertEqual(s, NewSet[String]("keep"), r)
+
+	s = NewSet[String]()
+	r.NoError(DecodeJSON(strings.NewReader(`["a", "b", "c"]`), s, WithMaxElements(0)))
+	r.Equal(3, s.Cardinality())
+}
+
+func Test_DecodeJSONReplace(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]("stale")
+	r.NoError(json.Unmarshal([]byte(`["a", "b"]`), s))
+	assertEqual(s, NewSet[String]("a", "b"), r)
+
+	r.NoError(DecodeJSON(strings.NewReader(`["c"]`), s))
+	assertEqual(s, NewSet[String]("c"), r)
+
+	r.NoError(DecodeJSON(strings.NewReader(`null`), s))
+	r.Zero(s.Cardinality())
+}
+
+func Test_DecodeJSONFailureKeepsSet(t *testing.T) {
+	r := require.New(t)
+
+	for _, s := range []Set[String]{
+		NewSet[String]("keep"),
+		NewThreadUnsafeSet[String]("keep"),
+		NewOrderedSet[String]("keep"),
+	} {
+		r.Error(json.Unmarshal([]byte(`["x", 1]`), s))
+		r.Error(s.UnmarshalJSON([]byte(`["x", "y"`)))
+		r.Error(DecodeJSON(strings.NewReader(`["x", "x"]`), s, WithMerge(), WithRejectDuplicates()))
+		r.Error(UnmarshalJSON([]byte(`["x", {}]`), s, WithMerge()))
+		assertEqual(s, NewSet[String]("keep"), r)
+	}
+}
+
+func Test_UnmarshalPanicUnlocks(t *testing.T) {
+	r := require.New(t)
+
+	// The key func panics on the nil element decoded from null.
+	s := NewKeyedSet(func(p *int) string { return strconv.Itoa(*p) }, nil)
+	ops := []func(){
+		func() { _ = s.UnmarshalJSON([]byte(`[null]`)) },
+		func() { _ = s.UnmarshalText([]byte(`null`)) },
+	}
+
+	var panicked int
+	var added bool
+	runWithTimeout(t, func() {
+		for _, op := range ops {
+			func() {
+				defer func() {
+					if recover() != nil {
+						panicked++
+					}
+				}()
+				op()
+			}()
+		}
+		added = s.Add(new(int))
+	})
+	r.Equal(len(ops), panicked)
+	r.True(added)
+}
+
+func Test_DecodeJSONMerge(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]("a")
+	r.NoError(DecodeJSON(strings.NewReader(`["b", "c"]`), s, WithMerge()))
+	assertEqual(s, NewSet[String]("a", "b", "c"), r)
+
+	r.NoError(DecodeJSON(strings.NewReader(`null`), s, WithMerge()))
+	r.Equal(3, s.Cardinality())
+}
+
+func Test_DecodeJSONRejectDuplicates(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]()
+	r.NoError(DecodeJSON(strings.NewReader(`["a", "b"]`), s, WithRejectDuplicates()))
+	r.Error(DecodeJSON(strings.NewReader(`["a", "b", "a"]`), s, WithRejectDuplicates()))
+
+	// Previous elements of the set are no duplicates when merging.
+	s = NewSet[String]("a")
+	r.NoError(DecodeJSON(strings.NewReader(`["a", "b"]`), s, WithMerge(), WithRejectDuplicates()))
+	assertEqual(s, NewSet[String]("a", "b"), r)
+	r.Error(DecodeJSON(strings.NewReader(`["c", "c"]`), s, WithMerge(), WithRejectDuplicates()))
+}
+
+func Test_UnmarshalJSONOptions(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSortedSet[Int](func(a, b Int) bool { return a < b }, 5)
+	r.NoError(UnmarshalJSON([]byte(`[3, 1, 2]`), Set[Int](s), WithMerge()))
+	r.Equal([]Int{1, 2, 3, 5}, s.ToSlice())
+
+	r.NoError(UnmarshalJSON([]byte(`[4]`), Set[Int](s)))
+	r.Equal([]Int{4}, s.ToSlice())
+
+	r.Error(UnmarshalJSON([]byte(`[1, 2, 3]`), Set[Int](s), WithMaxElements(2)))
+	r.Error(UnmarshalJSON([]byte(`[1, 1]`), NewThreadUnsafeSet[Int](), WithRejectDuplicates()))
+}
+
+func Test_UnmarshalJSONConcurrent(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[Int]()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	errs := make(chan error, 200)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			errs <- json.Unmarshal([]byte(`[1, 2, 3]`), s)
+			errs <- UnmarshalJSON([]byte(`[1, 2, 3]`), s)
+		}
+	}()
+	sizes := make([]int, 100)
+	go func() {
+		defer wg.Done()
+		for i := range sizes {
+			sizes[i] = s.Cardinality()
+			s.Each(func(Int) bool { return false })
+		}
+	}()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		r.NoError(err)
+	}
+	// Decoding replaces the elements under a single lock.
+	for _, n := range sizes {
+		if n != 0 {
+			r.Equal(3, n)
+		}
+	}
+}
+
+func Test_DecodeJSONTakesOverElements(t *testing.T) {
+	r := require.New(t)
+
+	for _, s := range []Set[String]{
+		NewSet[String]("x"),
+		NewThreadUnsafeSet[String]("x"),
+		NewOrderedSet[String]("x"),
+		NewThreadUnsafeOrderedSet[String]("x"),
+		NewSortedSet[String](func(a, b String) bool { return a < b }, "x"),
+		NewThreadUnsafeSortedSet[String](func(a, b String) bool { return a < b }, "x"),
+	} {
+		e := emptyClone(s)
+		r.IsType(s, e)
+		r.Zero(e.Cardinality())
+		r.Equal(1, s.Cardinality())
+
+		r.NoError(DecodeJSON(strings.NewReader(`["c", "a", "b"]`), s))
+		r.True(s.Add("d"))
+		r.ElementsMatch([]String{"a", "b", "c", "d"}, s.ToSlice())
+	}
+
+	// Ordered sets take over the list of the decoded set.
+	o := NewThreadUnsafeOrderedSet[String]("x")
+	r.NoError(json.Unmarshal([]byte(`["c", "a", "b"]`), o))
+	o.Add("d")
+	r.True(o.MoveToBack("a"))
+	r.Equal([]String{"c", "b", "d", "a"}, o.ToSlice())
+	v, ok := o.Pop()
+	r.True(ok)
+	r.Equal(String("c"), v)
+
+	// Decoding swaps in the buckets of the decoded set rather than
+	// copying the elements.
+	u := NewThreadUnsafeSet[String]().(*threadUnsafeSet[string, String])
+	src := emptyClone[String](u).(*threadUnsafeSet[string, String])
+	src.Add("a")
+	replaceAll[String](u, src)
+	r.Equal(reflect.ValueOf(src.buckets).Pointer(), reflect.ValueOf(u.buckets).Pointer())
+	r.True(u.Contains("a"))
+}
@@ -0,0 +1,135 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// instantKey keys times by the instant they denote, regardless of their
+// location.
+func instantKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func instantEqual(a, b time.Time) bool {
+	return a.Equal(b)
+}
+
+func Test_KeyedSet(t *testing.T) {
+	safe := func(vals ...time.Time) Set[time.Time] {
+		return NewKeyedSet(instantKey, instantEqual, vals...)
+	}
+	unsafe := func(vals ...time.Time) Set[time.Time] {
+		return NewThreadUnsafeKeyedSet(instantKey, instantEqual, vals...)
+	}
+	runSafeUnsafe(t, safe, unsafe, func(t *testing.T, newSet func(...time.Time) Set[time.Time]) {
+		r := require.New(t)
+
+		berlin := time.FixedZone("CET", 60*60)
+		noon := time.Date(2022, 4, 22, 12, 0, 0, 0, time.UTC)
+		evening := noon.Add(6 * time.Hour)
+
+		s := newSet(noon, evening)
+		r.Equal(2, s.Cardinality())
+		r.False(s.Add(noon.In(berlin)), "the same instant in another location is equal")
+		r.True(s.Contains(evening.In(berlin)))
+
+		c := s.Clone()
+		c.Clear()
+		r.True(c.Add(noon))
+		r.False(c.Add(noon.In(berlin)), "cleared clones keep the key and equal funcs")
+
+		other := newSet(evening, evening.Add(time.Hour))
+		assertEqual(s.Intersect(other), newSet(evening), r)
+		assertEqual(s.Union(other), newSet(noon, evening, evening.Add(time.Hour)), r)
+		assertEqual(s.Difference(other), newSet(noon), r)
+
+		idx := s.(KeyIndex[string, time.Time])
+		v, ok := idx.Get(instantKey(noon))
+		r.True(ok)
+		r.True(v.Equal(noon))
+
+		b, err := json.Marshal(s)
+		r.NoError(err)
+		d := newSet()
+		r.NoError(json.Unmarshal(b, d))
+		assertEqual(s, d, r)
+
+		s.Remove(noon.In(berlin))
+		r.False(s.Contains(noon))
+	})
+}
+
+func Test_KeyedSetCollision(t *testing.T) {
+	r := require.New(t)
+
+	// Words are keyed by their first letter, so keys collide often.
+	s := NewKeyedSet(func(w string) string { return w[:1] }, func(a, b string) bool { return a == b },
+		"apple", "avocado", "banana")
+	r.Equal(3, s.Cardinality())
+	r.True(s.Contains("apple", "avocado"))
+	r.False(s.Contains("apricot"))
+
+	// Without an equal func, the key alone identifies elements.
+	u := NewThreadUnsafeKeyedSet(strings.ToLower, nil, "Go", "go", "GO", "Rust")
+	r.Equal(2, u.Cardinality())
+	r.True(u.Contains("gO"))
+	r.True(u.Intersect(NewKeyedSet(strings.ToLower, nil, "go")).Contains("GO"))
+}
+
+func Test_KeyedSetWith(t *testing.T) {
+	safe := func(vals ...string) Set[string] {
+		return NewKeyedSetWith(strings.ToLower, nil, KeepExisting[string], vals...)
+	}
+	unsafe := func(vals ...string) Set[string] {
+		return NewThreadUnsafeKeyedSetWith(strings.ToLower, nil, KeepExisting[string], vals...)
+	}
+	runSafeUnsafe(t, safe, unsafe, func(t *testing.T, newSet func(...string) Set[string]) {
+		r := require.New(t)
+
+		s := newSet("Go", "GO")
+		r.Equal([]string{"Go"}, s.ToSlice(), "the first of vals is kept")
+		r.False(s.Add("go"))
+		r.Equal([]string{"Go"}, s.ToSlice())
+		r.Equal([]string{"Go"}, s.Union(newSet("gO")).ToSlice())
+		r.Equal([]string{"gO"}, s.UnionWith(newSet("gO"), ReplaceExisting[string]).ToSlice())
+
+		c := s.Clone()
+		r.False(c.Add("GO"))
+		r.Equal([]string{"Go"}, c.ToSlice(), "clones keep the merge func")
+
+		old, ok := s.Replace("GO")
+		r.True(ok)
+		r.Equal("Go", old)
+		r.Equal([]string{"GO"}, s.ToSlice())
+	})
+}
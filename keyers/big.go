@@ -0,0 +1,72 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package keyers
+
+import "math/big"
+
+// BigInt adapts a *big.Int, which must not be modified while the BigInt
+// is in a set. A nil Int is equal only to another nil Int.
+type BigInt struct {
+	*big.Int
+}
+
+// NewBigInt returns a BigInt set to x.
+func NewBigInt(x int64) BigInt {
+	return BigInt{Int: big.NewInt(x)}
+}
+
+func (b BigInt) Equal(other any) bool {
+	o, ok := other.(BigInt)
+	if !ok {
+		return false
+	}
+	if b.Int == nil || o.Int == nil {
+		return b.Int == o.Int
+	}
+	return b.Int.Cmp(o.Int) == 0
+}
+
+// Key returns the value in base 62, or "" for a nil Int.
+func (b BigInt) Key() string {
+	if b.Int == nil {
+		return ""
+	}
+	return b.Int.Text(62)
+}
+
+func (b *BigInt) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		b.Int = nil
+		return nil
+	}
+	b.Int = new(big.Int)
+	return b.Int.UnmarshalJSON(text)
+}
+
+func (b *BigInt) UnmarshalText(text []byte) error {
+	b.Int = new(big.Int)
+	return b.Int.UnmarshalText(text)
+}
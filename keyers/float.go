@@ -0,0 +1,94 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package keyers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Float64 adapts a float64. Unlike with ==, NaN is equal to NaN, so that a
+// set holds at most one NaN, and 0 and -0 are equal, so that a set holds
+// at most one zero.
+//
+// As JSON has no representation of NaN and the infinities, they are
+// encoded as the strings "NaN", "+Inf" and "-Inf".
+type Float64 float64
+
+func (f Float64) Equal(other any) bool {
+	o, ok := other.(Float64)
+	if !ok {
+		return false
+	}
+	return f == o || math.IsNaN(float64(f)) && math.IsNaN(float64(o))
+}
+
+func (f Float64) Key() string {
+	if f == 0 {
+		// Normalise -0.
+		return "0"
+	}
+	// FormatFloat spells every NaN "NaN".
+	return strconv.FormatFloat(float64(f), 'g', -1, 64)
+}
+
+func (f Float64) MarshalJSON() ([]byte, error) {
+	switch v := float64(f); {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return json.Marshal(float64(f))
+}
+
+func (f *Float64) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		switch s {
+		case "NaN":
+			*f = Float64(math.NaN())
+		case "+Inf":
+			*f = Float64(math.Inf(1))
+		case "-Inf":
+			*f = Float64(math.Inf(-1))
+		default:
+			return fmt.Errorf("keyers: cannot decode JSON string %q into a Float64", s)
+		}
+		return nil
+	}
+
+	var v float64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*f = Float64(v)
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package keyers provides EqualKeyer adapters for common types of the
+// standard library, so that values of these types can be stored in sets
+// created by mapset.NewSet without writing wrapper types:
+//
+//	ports := mapset.NewSet[keyers.Uint16](80, 443)
+//	addrs := mapset.NewSet(keyers.Addr{Addr: netip.MustParseAddr("::1")})
+//
+// All adapters round-trip through JSON in the encoding of the type they
+// adapt, unless noted otherwise.
+package keyers
+
+import "strconv"
+
+// Int adapts an int, its key is its decimal representation.
+type Int int
+
+func (i Int) Equal(other any) bool {
+	o, ok := other.(Int)
+	return ok && i == o
+}
+
+func (i Int) Key() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Int8 adapts an int8, its key is its decimal representation.
+type Int8 int8
+
+func (i Int8) Equal(other any) bool {
+	o, ok := other.(Int8)
+	return ok && i == o
+}
+
+func (i Int8) Key() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Int16 adapts an int16, its key is its decimal representation.
+type Int16 int16
+
+func (i Int16) Equal(other any) bool {
+	o, ok := other.(Int16)
+	return ok && i == o
+}
+
+func (i Int16) Key() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Int32 adapts an int32, its key is its decimal representation.
+type Int32 int32
+
+func (i Int32) Equal(other any) bool {
+	o, ok := other.(Int32)
+	return ok && i == o
+}
+
+func (i Int32) Key() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Int64 adapts an int64, its key is its decimal representation.
+type Int64 int64
+
+func (i Int64) Equal(other any) bool {
+	o, ok := other.(Int64)
+	return ok && i == o
+}
+
+func (i Int64) Key() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Uint adapts an uint, its key is its decimal representation.
+type Uint uint
+
+func (i Uint) Equal(other any) bool {
+	o, ok := other.(Uint)
+	return ok && i == o
+}
+
+func (i Uint) Key() string {
+	return strconv.FormatUint(uint64(i), 10)
+}
+
+// Uint8 adapts an uint8, its key is its decimal representation.
+type Uint8 uint8
+
+func (i Uint8) Equal(other any) bool {
+	o, ok := other.(Uint8)
+	return ok && i == o
+}
+
+func (i Uint8) Key() string {
+	return strconv.FormatUint(uint64(i), 10)
+}
+
+// Uint16 adapts an uint16, its key is its decimal representation.
+type Uint16 uint16
+
+func (i Uint16) Equal(other any) bool {
+	o, ok := other.(Uint16)
+	return ok && i == o
+}
+
+func (i Uint16) Key() string {
+	return strconv.FormatUint(uint64(i), 10)
+}
+
+// Uint32 adapts an uint32, its key is its decimal representation.
+type Uint32 uint32
+
+func (i Uint32) Equal(other any) bool {
+	o, ok := other.(Uint32)
+	return ok && i == o
+}
+
+func (i Uint32) Key() string {
+	return strconv.FormatUint(uint64(i), 10)
+}
+
+// Uint64 adapts an uint64, its key is its decimal representation.
+type Uint64 uint64
+
+func (i Uint64) Equal(other any) bool {
+	o, ok := other.(Uint64)
+	return ok && i == o
+}
+
+func (i Uint64) Key() string {
+	return strconv.FormatUint(uint64(i), 10)
+}
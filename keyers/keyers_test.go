@@ -0,0 +1,229 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package keyers
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net/netip"
+	"testing"
+	"time"
+
+	mapset "github.com/NectGmbH/golang-set/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip encodes s to JSON and decodes it into a new set, which it
+// returns along with the encoding.
+func roundTrip[T mapset.EqualKeyer](r *require.Assertions, s mapset.Set[T]) (mapset.Set[T], string) {
+	b, err := json.Marshal(s)
+	r.NoError(err)
+	d := mapset.NewSet[T]()
+	r.NoError(json.Unmarshal(b, d))
+	r.True(s.Equal(d), "%s did not round-trip", b)
+	return d, string(b)
+}
+
+func Test_Integers(t *testing.T) {
+	r := require.New(t)
+
+	s := mapset.NewSet[Int64](math.MinInt64, -1, 0, 1, math.MaxInt64)
+	r.False(s.Add(-1))
+	r.Equal("-9223372036854775808", Int64(math.MinInt64).Key())
+	roundTrip(r, s)
+
+	u := mapset.NewSet[Uint64](0, math.MaxUint64)
+	r.Equal("18446744073709551615", Uint64(math.MaxUint64).Key())
+	roundTrip(r, u)
+
+	r.False(Int8(1).Equal(Int16(1)), "integers of different widths are distinct")
+	roundTrip(r, mapset.NewSet[Uint8](1, 255))
+	roundTrip(r, mapset.NewSet[Int32](-5, 5))
+
+	b := mapset.NewBitSet[Uint16](80, 443)
+	r.True(b.Contains(443))
+}
+
+func Test_Float64(t *testing.T) {
+	r := require.New(t)
+
+	s := mapset.NewSet[Float64](1.5, Float64(math.NaN()), 0)
+	r.False(s.Add(Float64(math.NaN())), "NaN is equal to NaN")
+	r.False(s.Add(Float64(math.Copysign(0, -1))), "-0 is equal to 0")
+	r.True(s.Add(Float64(math.Inf(1))))
+	r.True(s.Add(Float64(math.Inf(-1))))
+	r.Equal(5, s.Cardinality())
+	r.True(s.Contains(Float64(math.NaN())))
+
+	_, enc := roundTrip(r, s)
+	r.Contains(enc, `"NaN"`)
+	r.Contains(enc, `"+Inf"`)
+	r.Contains(enc, `"-Inf"`)
+
+	var f Float64
+	r.Error(json.Unmarshal([]byte(`"1.5"`), &f))
+	r.NoError(json.Unmarshal([]byte(`2.25`), &f))
+	r.Equal(Float64(2.25), f)
+}
+
+func Test_Bytes(t *testing.T) {
+	r := require.New(t)
+
+	s := mapset.NewSet[Bytes]([]byte("abc"), []byte{0, 1})
+	r.False(s.Add(Bytes("abc")), "bytes are compared by content")
+	r.True(s.Contains([]byte{0, 1}))
+	_, enc := roundTrip(r, s)
+	r.Contains(enc, `"YWJj"`)
+
+	added, err := mapset.TryAdd(s, nil)
+	r.NoError(err, "nil bytes have a key")
+	r.True(added)
+	r.True(s.Contains(Bytes{}), "nil bytes equal empty bytes")
+}
+
+func Test_Time(t *testing.T) {
+	r := require.New(t)
+
+	noon := time.Date(2022, 4, 22, 12, 0, 0, 5, time.UTC)
+	s := mapset.NewSet[Time](Time{noon}, Time{noon.Add(time.Hour)})
+	r.False(s.Add(Time{noon.In(time.FixedZone("CET", 3600))}), "the same instant is equal")
+	r.True(s.Contains(Time{time.Unix(0, noon.UnixNano())}))
+	r.True(s.Add(Time{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)}))
+	r.True(s.Add(Time{}))
+	roundTrip(r, s)
+
+	now := time.Now()
+	r.True(Time{now}.Equal(Time{now.Round(0)}), "the monotonic clock reading is ignored")
+	r.Equal(Time{now}.Key(), Time{now.Round(0)}.Key())
+}
+
+func Test_Addr(t *testing.T) {
+	r := require.New(t)
+
+	s := mapset.NewSet(
+		Addr{netip.MustParseAddr("192.0.2.1")},
+		Addr{netip.MustParseAddr("::ffff:192.0.2.1")},
+		Addr{netip.MustParseAddr("fe80::1%eth0")},
+		Addr{netip.MustParseAddr("fe80::1")},
+		Addr{},
+		Addr{netip.IPv6Unspecified()},
+	)
+	r.Equal(6, s.Cardinality(), "mapped addresses and zones are distinct")
+	r.Len(s.(mapset.KeyIndex[string, Addr]).Keys(), 6, "distinct addresses have distinct keys")
+	for _, a := range s.ToSlice() {
+		b, err := a.MarshalBinary()
+		r.NoError(err)
+		r.Equal(string(b), a.Key())
+	}
+	r.False(s.Add(Addr{netip.MustParseAddr("FE80::1%eth0")}))
+	roundTrip(r, s)
+
+	addr := Addr{netip.MustParseAddr("2001:db8::1")}
+	r.LessOrEqual(testing.AllocsPerRun(100, func() { _ = addr.Key() }), 1.0)
+}
+
+func Test_Prefix(t *testing.T) {
+	r := require.New(t)
+
+	s := mapset.NewSet(
+		Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		Prefix{netip.MustParsePrefix("10.0.0.0/16")},
+		Prefix{netip.MustParsePrefix("10.0.0.1/8")},
+		Prefix{netip.MustParsePrefix("2001:db8::/32")},
+		Prefix{netip.MustParsePrefix("::ffff:10.0.0.0/8")},
+		Prefix{},
+	)
+	r.Equal(6, s.Cardinality())
+	r.Len(s.(mapset.KeyIndex[string, Prefix]).Keys(), 6, "distinct prefixes have distinct keys")
+	for _, p := range s.ToSlice() {
+		b, err := p.MarshalBinary()
+		r.NoError(err)
+		r.Equal(string(b), p.Key())
+	}
+	r.False(s.Add(Prefix{netip.MustParsePrefix("10.0.0.0/8")}))
+	roundTrip(r, s)
+}
+
+func Test_URL(t *testing.T) {
+	r := require.New(t)
+
+	mustParse := func(s string) URL {
+		u, err := ParseURL(s)
+		r.NoError(err)
+		return u
+	}
+
+	equivalent := [][]string{
+		{"http://example.com", "HTTP://Example.COM/", "http://example.com:80/"},
+		{"https://example.com/a/b", "https://example.com:443/a/./c/../b"},
+		{"https://example.com/~a%2fb", "https://example.com/%7Ea%2Fb"},
+		{"https://example.com/?q=%c3%a4#top", "https://example.com/?q=%C3%A4#top"},
+		{"mailto:gopher@example.com", "MAILTO:gopher@example.com"},
+	}
+	for _, urls := range equivalent {
+		s := mapset.NewSet(mustParse(urls[0]))
+		for _, u := range urls[1:] {
+			r.False(s.Add(mustParse(u)), "%s should be equivalent to %s", u, urls[0])
+		}
+	}
+
+	distinct := []string{
+		"https://example.com/a",
+		"https://example.com/a/",
+		"https://example.com/A",
+		"https://example.com/a%2Fb",
+		"https://example.com/a/b",
+		"https://example.com:8443/a",
+		"http://example.com/a",
+		"https://example.com/a?x=1",
+		"https://example.com/a#x",
+		"https://user@example.com/a",
+	}
+	s := mapset.NewSet[URL]()
+	for _, u := range distinct {
+		r.True(s.Add(mustParse(u)), "%s should be distinct", u)
+	}
+
+	d, enc := roundTrip(r, s)
+	r.Contains(enc, `"https://example.com/a%2Fb"`)
+	r.True(d.Contains(mustParse("https://EXAMPLE.com:443/a")))
+
+	var u URL
+	r.Error(json.Unmarshal([]byte(`"%zz"`), &u))
+}
+
+func Test_BigInt(t *testing.T) {
+	r := require.New(t)
+
+	huge, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	r.True(ok)
+	s := mapset.NewSet(NewBigInt(-1), NewBigInt(0), BigInt{huge}, BigInt{})
+	r.False(s.Add(NewBigInt(-1)))
+	r.False(s.Add(BigInt{}))
+	r.True(s.Add(NewBigInt(1)))
+	roundTrip(r, s)
+}
@@ -0,0 +1,82 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package keyers
+
+import "net/netip"
+
+// Addr adapts a netip.Addr. IPv4 addresses and their IPv4-mapped IPv6
+// counterparts are distinct, as are addresses with different zones.
+type Addr struct {
+	netip.Addr
+}
+
+func (a Addr) Equal(other any) bool {
+	o, ok := other.(Addr)
+	return ok && a.Addr == o.Addr
+}
+
+// Key returns the address in the binary form of MarshalBinary: no bytes
+// for the zero Addr, 4 for IPv4 and 16 followed by the zone for IPv6
+// addresses, so that the length tells the families apart.
+func (a Addr) Key() string {
+	b := a.As16()
+	switch {
+	case a.Is4():
+		return string(b[12:])
+	case a.Is6():
+		return string(b[:]) + a.Zone()
+	}
+	return ""
+}
+
+// Prefix adapts a netip.Prefix. Prefixes are equal only if both their
+// addresses and their lengths are, so 10.0.0.1/8 and 10.0.0.0/8 are
+// distinct; use netip.Prefix.Masked to normalise them beforehand.
+type Prefix struct {
+	netip.Prefix
+}
+
+func (p Prefix) Equal(other any) bool {
+	o, ok := other.(Prefix)
+	return ok && p.Prefix == o.Prefix
+}
+
+// Key returns the prefix in the binary form of MarshalBinary: the address
+// as by Addr.Key followed by the length.
+func (p Prefix) Key() string {
+	var b [17]byte
+	addr := p.Addr()
+	a := addr.As16()
+	k := b[:0]
+	switch {
+	case addr.Is4():
+		k = append(k, a[12:]...)
+	case addr.Is6():
+		k = append(k, a[:]...)
+	}
+	k = append(k, byte(p.Bits()))
+	return string(k)
+}
@@ -0,0 +1,175 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package keyers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// URL adapts a url.URL. URLs are equal if they are equivalent after the
+// normalisations of RFC 3986, section 6.2.2 and 6.2.3: the scheme and
+// host are compared case insensitively, percent-encodings are compared in
+// upper case and only where needed, dot segments are removed from the
+// path, an empty path is "/" and default ports of http and https are
+// dropped. The original spelling is kept, it is used for its JSON and
+// text encoding.
+type URL struct {
+	url.URL
+}
+
+// ParseURL parses s into a URL.
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+	return URL{URL: *u}, nil
+}
+
+func (u URL) Equal(other any) bool {
+	o, ok := other.(URL)
+	return ok && u.Key() == o.Key()
+}
+
+// Key returns the normalised form of the URL.
+func (u URL) Key() string {
+	n := u.URL
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Host = strings.ToLower(n.Host)
+	if port := n.Port(); port == "80" && n.Scheme == "http" || port == "443" && n.Scheme == "https" {
+		n.Host = strings.TrimSuffix(n.Host, ":"+port)
+	}
+
+	path := removeDotSegments(normalizeEscapes(n.EscapedPath()))
+	if path == "" && n.Host != "" {
+		path = "/"
+	}
+	query, forceQuery := n.RawQuery, n.ForceQuery
+	n.Opaque = normalizeEscapes(n.Opaque)
+	n.Path, n.RawPath, n.RawQuery, n.ForceQuery = "", "", "", false
+	n.Fragment, n.RawFragment = "", ""
+
+	var b strings.Builder
+	b.WriteString(n.String())
+	b.WriteString(path)
+	if query != "" || forceQuery {
+		b.WriteByte('?')
+		b.WriteString(normalizeEscapes(query))
+	}
+	if u.Fragment != "" || u.RawFragment != "" {
+		b.WriteByte('#')
+		b.WriteString(normalizeEscapes(u.EscapedFragment()))
+	}
+	return b.String()
+}
+
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.URL.String()), nil
+}
+
+func (u *URL) UnmarshalText(text []byte) error {
+	p, err := ParseURL(string(text))
+	if err != nil {
+		return err
+	}
+	*u = p
+	return nil
+}
+
+// normalizeEscapes upper cases the hex digits of percent-encodings in s
+// and decodes those of unreserved characters.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// removeDotSegments removes "." and ".." segments from path as described
+// in RFC 3986, section 5.2.4.
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	out := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch seg {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 || len(out) == 1 && out[0] != "" {
+				out = out[:len(out)-1]
+			}
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, seg)
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	}
+	return c - 'a' + 10
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
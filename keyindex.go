@@ -0,0 +1,220 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// KeyIndex is implemented by the sets created by NewSet, NewKeyerSet,
+// NewKeyedSet and their thread-unsafe counterparts. It addresses the elements of a set by
+// their Key(), so that a set can double as an index of its elements:
+//
+//	users := mapset.NewSet[User]()
+//	...
+//	u, ok := users.(mapset.KeyIndex[string, User]).Get(id)
+//
+// Elements whose keys collide but which are not Equal are stored under the
+// same key, the first of them which was inserted is the one returned.
+type KeyIndex[K comparable, T any] interface {
+	// Returns the element stored under key.
+	Get(key K) (T, bool)
+
+	// Returns whether elements are stored under
+	// all of the given keys.
+	ContainsKey(keys ...K) bool
+
+	// Removes the element Get returns for key and
+	// returns it. Other elements stored under key
+	// stay in the set, Get returns the next of them.
+	RemoveKey(key K) (T, bool)
+
+	// Returns the keys of all elements of the set,
+	// every key once.
+	Keys() []K
+}
+
+// Rehasher is implemented by the same sets as KeyIndex. It detects and
+// repairs key drift, which occurs when elements are mutated in place, for
+// instance through pointers, while they are in a set, so that their Key()
+// no longer matches the key they are stored under. Drifted elements cannot
+// be found by Contains, Remove or Get until the set is rehashed.
+type Rehasher[K comparable, T any] interface {
+	// Returns the elements whose current key differs
+	// from the key they are stored under.
+	Verify() []KeyDrift[K, T]
+
+	// Stores the elements reported by Verify under
+	// their current key.
+	Rehash() RehashReport[K, T]
+}
+
+// KeyDrift describes an element whose current key differs from the key it
+// is stored under.
+type KeyDrift[K comparable, T any] struct {
+	Elem      T
+	StoredKey K
+	Key       K
+}
+
+// RehashReport describes the outcome of a Rehash.
+type RehashReport[K comparable, T any] struct {
+	// Moved holds the elements which have been stored under
+	// their current key, as reported by Verify beforehand.
+	Moved []KeyDrift[K, T]
+
+	// Collisions holds the moved elements which share their
+	// current key with other elements that they are not
+	// Equal to. They are in the set.
+	Collisions []T
+
+	// Duplicates holds the moved elements which turned out
+	// to be Equal to an element already stored under their
+	// current key. They have been dropped from the set, the
+	// element already stored is kept.
+	Duplicates []T
+}
+
+// Assert concrete types adhere to KeyIndex and Rehasher interfaces.
+var (
+	_ KeyIndex[string, String] = (*threadUnsafeSet[string, String])(nil)
+	_ KeyIndex[string, String] = (*threadSafeKeyerSet[string, String])(nil)
+	_ Rehasher[string, String] = (*threadUnsafeSet[string, String])(nil)
+	_ Rehasher[string, String] = (*threadSafeKeyerSet[string, String])(nil)
+)
+
+func (s *threadUnsafeSet[K, T]) Get(key K) (v T, ok bool) {
+	if bucket := s.buckets[key]; len(bucket) > 0 {
+		return bucket[0], true
+	}
+	return
+}
+
+func (s *threadUnsafeSet[K, T]) ContainsKey(keys ...K) bool {
+	for _, key := range keys {
+		if _, ok := s.buckets[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *threadUnsafeSet[K, T]) RemoveKey(key K) (v T, ok bool) {
+	if _, ok = s.buckets[key]; !ok {
+		return
+	}
+	return s.removeAt(key, 0), true
+}
+
+func (s *threadUnsafeSet[K, T]) Keys() []K {
+	keys := make([]K, 0, len(s.buckets))
+	for key := range s.buckets {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *threadUnsafeSet[K, T]) Verify() []KeyDrift[K, T] {
+	var drifts []KeyDrift[K, T]
+	for key, bucket := range s.buckets {
+		for _, elem := range bucket {
+			if k := s.key(elem); k != key {
+				drifts = append(drifts, KeyDrift[K, T]{Elem: elem, StoredKey: key, Key: k})
+			}
+		}
+	}
+	return drifts
+}
+
+func (s *threadUnsafeSet[K, T]) Rehash() RehashReport[K, T] {
+	report := RehashReport[K, T]{Moved: s.Verify()}
+
+	// Take all drifted elements out first, so that they are not mistaken
+	// for duplicates of each other under their stale keys.
+	for _, drift := range report.Moved {
+		bucket := s.buckets[drift.StoredKey]
+		for i, elem := range bucket {
+			if s.key(elem) != drift.StoredKey {
+				s.removeAt(drift.StoredKey, i)
+				break
+			}
+		}
+	}
+
+	for _, drift := range report.Moved {
+		switch _, i := s.find(drift.Elem); {
+		case i >= 0:
+			report.Duplicates = append(report.Duplicates, drift.Elem)
+			continue
+		case len(s.buckets[drift.Key]) > 0:
+			report.Collisions = append(report.Collisions, drift.Elem)
+		}
+		s.buckets[drift.Key] = append(s.buckets[drift.Key], drift.Elem)
+		s.size++
+	}
+	return report
+}
+
+// threadSafeKeyerSet guards a threadUnsafeSet with a sync.RWMutex.
+type threadSafeKeyerSet[K comparable, T any] struct {
+	threadSafeSet[T]
+}
+
+func (s *threadSafeKeyerSet[K, T]) keyed() *threadUnsafeSet[K, T] {
+	return s.uss.(*threadUnsafeSet[K, T])
+}
+
+func (s *threadSafeKeyerSet[K, T]) Get(key K) (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.keyed().Get(key)
+}
+
+func (s *threadSafeKeyerSet[K, T]) ContainsKey(keys ...K) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.keyed().ContainsKey(keys...)
+}
+
+func (s *threadSafeKeyerSet[K, T]) RemoveKey(key K) (T, bool) {
+	s.lock()
+	defer s.Unlock()
+	return s.keyed().RemoveKey(key)
+}
+
+func (s *threadSafeKeyerSet[K, T]) Keys() []K {
+	s.RLock()
+	defer s.RUnlock()
+	return s.keyed().Keys()
+}
+
+func (s *threadSafeKeyerSet[K, T]) Verify() []KeyDrift[K, T] {
+	s.RLock()
+	defer s.RUnlock()
+	return s.keyed().Verify()
+}
+
+func (s *threadSafeKeyerSet[K, T]) Rehash() RehashReport[K, T] {
+	s.lock()
+	defer s.Unlock()
+	return s.keyed().Rehash()
+}
@@ -0,0 +1,201 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// user is identified by its id alone.
+type user struct {
+	id   string
+	name string
+}
+
+func (u user) Equal(other any) bool {
+	o, ok := other.(user)
+	return ok && u.id == o.id
+}
+
+func (u user) Key() string {
+	return u.id
+}
+
+func Test_KeyIndex(t *testing.T) {
+	runSafeUnsafe(t, NewSet[user], NewThreadUnsafeSet[user], func(t *testing.T, newSet func(...user) Set[user]) {
+		r := require.New(t)
+		s := newSet()
+
+		s.Add(user{id: "1", name: "ann"})
+		s.Add(user{id: "2", name: "bob"})
+
+		idx, ok := s.(KeyIndex[string, user])
+		r.True(ok)
+
+		u, ok := idx.Get("1")
+		r.True(ok)
+		r.Equal("ann", u.name)
+		_, ok = idx.Get("3")
+		r.False(ok)
+
+		r.True(idx.ContainsKey("1", "2"))
+		r.True(idx.ContainsKey())
+		r.False(idx.ContainsKey("1", "3"))
+
+		keys := idx.Keys()
+		sort.Strings(keys)
+		r.Equal([]string{"1", "2"}, keys)
+
+		u, ok = idx.RemoveKey("2")
+		r.True(ok)
+		r.Equal("bob", u.name)
+		_, ok = idx.RemoveKey("2")
+		r.False(ok)
+		r.Equal(1, s.Cardinality())
+		r.False(s.Contains(user{id: "2"}))
+
+		// Sets derived from s are indexed as well.
+		_, ok = s.Union(s).(KeyIndex[string, user])
+		r.True(ok)
+		_, ok = s.Clone().(KeyIndex[string, user])
+		r.True(ok)
+	})
+}
+
+func Test_KeyIndexCollision(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[hashed](4, 1, 7, 2)
+	idx := s.(KeyIndex[string, hashed])
+
+	v, ok := idx.Get("1")
+	r.True(ok)
+	r.Equal(hashed(4), v, "the first inserted element is returned")
+	r.Len(idx.Keys(), 2)
+
+	v, ok = idx.RemoveKey("1")
+	r.True(ok)
+	r.Equal(hashed(4), v)
+	r.Equal(3, s.Cardinality(), "colliding elements are kept")
+	r.True(s.Contains(1, 7, 2))
+
+	v, ok = idx.Get("1")
+	r.True(ok)
+	r.Equal(hashed(1), v, "the next inserted element is returned")
+	idx.RemoveKey("1")
+	idx.RemoveKey("1")
+	r.False(idx.ContainsKey("1"))
+	r.Equal([]hashed{2}, s.ToSlice())
+}
+
+func Test_KeyerSetKeyIndex(t *testing.T) {
+	r := require.New(t)
+
+	s := NewKeyerSet[int, KeyedInt](1, 2, 3)
+	idx := s.(KeyIndex[int, KeyedInt])
+	r.True(idx.ContainsKey(1, 2, 3))
+
+	v, ok := idx.Get(2)
+	r.True(ok)
+	r.Equal(KeyedInt(2), v)
+}
+
+// mutable is stored by pointer and identified by its name, which may be
+// changed in place.
+type mutable struct {
+	name string
+}
+
+func (m *mutable) Equal(other any) bool {
+	o, ok := other.(*mutable)
+	return ok && m.name == o.name
+}
+
+func (m *mutable) Key() string {
+	return m.name
+}
+
+func Test_Rehash(t *testing.T) {
+	runSafeUnsafe(t, NewSet[*mutable], NewThreadUnsafeSet[*mutable], func(t *testing.T, newSet func(...*mutable) Set[*mutable]) {
+		r := require.New(t)
+		s := newSet()
+
+		alise, bob, john := &mutable{"Alise"}, &mutable{"Bob"}, &mutable{"John"}
+		s.Add(alise)
+		s.Add(bob)
+		s.Add(john)
+		s.Add(&mutable{"Nick"})
+
+		rh := s.(Rehasher[string, *mutable])
+		r.Empty(rh.Verify())
+		r.Empty(rh.Rehash().Moved)
+
+		alise.name = "Alice"
+		r.False(s.Contains(&mutable{"Alice"}), "drifted elements cannot be found")
+		r.Equal([]KeyDrift[string, *mutable]{{Elem: alise, StoredKey: "Alise", Key: "Alice"}}, rh.Verify())
+
+		report := rh.Rehash()
+		r.Len(report.Moved, 1)
+		r.Empty(report.Collisions)
+		r.Empty(report.Duplicates)
+		r.Empty(rh.Verify())
+		r.True(s.Contains(&mutable{"Alice"}))
+		r.Equal(4, s.Cardinality())
+
+		// John becomes a duplicate of Bob.
+		john.name = "Bob"
+		r.Len(rh.Verify(), 1)
+		report = rh.Rehash()
+		r.Equal([]*mutable{john}, report.Duplicates)
+		r.Equal(3, s.Cardinality())
+
+		idx := s.(KeyIndex[string, *mutable])
+		v, ok := idx.Get("Bob")
+		r.True(ok)
+		r.Same(bob, v, "the element already stored is kept")
+		r.False(idx.ContainsKey("John"))
+	})
+}
+
+func Test_RehashCollision(t *testing.T) {
+	r := require.New(t)
+
+	s := NewThreadUnsafeSet[hashed]().(*threadUnsafeSet[string, hashed])
+	s.Add(0)
+	s.Add(1)
+	// Simulate an element whose key changed while it was stored.
+	s.buckets["1"] = append(s.buckets["1"], 3)
+	s.size++
+
+	report := s.Rehash()
+	r.Equal([]KeyDrift[string, hashed]{{Elem: 3, StoredKey: "1", Key: "0"}}, report.Moved)
+	r.Equal([]hashed{3}, report.Collisions)
+	r.Equal(3, s.Cardinality())
+	r.True(s.Contains(0, 1, 3))
+}
@@ -0,0 +1,73 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+// MergeFunc resolves the conflict between an element of a set and an Equal
+// element added to it, by returning the element the set keeps. The result
+// must be Equal to both elements and have the same key.
+//
+// Use it to merge versioned records, for instance:
+//
+//	newest := func(old, new Record) Record {
+//		if new.Version > old.Version {
+//			return new
+//		}
+//		return old
+//	}
+//	s.AddWith(r, newest)
+type MergeFunc[T any] func(old, new T) T
+
+// KeepExisting is the MergeFunc keeping the element already in the set.
+// Pass it to AddWith, UnionWith or NewKeyedSetWith to add elements only if
+// no Equal element is in the set yet.
+func KeepExisting[T any](old, _ T) T {
+	return old
+}
+
+// ReplaceExisting is the MergeFunc replacing the element already in the
+// set by the added one, it is the policy of Add, Union and Replace.
+func ReplaceExisting[T any](_, new T) T {
+	return new
+}
+
+// replace adds v to s, replacing an Equal element, which it returns.
+func replace[T any](s Set[T], v T) (old T, ok bool) {
+	ok = !s.AddWith(v, func(o, n T) T {
+		old = o
+		return n
+	})
+	return
+}
+
+// unionWith adds all elements of src to dst, resolving conflicts with
+// merge, and returns dst.
+func unionWith[T any](dst, src Set[T], merge MergeFunc[T]) Set[T] {
+	src.Each(func(elem T) bool {
+		dst.AddWith(elem, merge)
+		return false
+	})
+	return dst
+}
@@ -0,0 +1,157 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// record is identified by its id, its version tells apart its revisions.
+type record struct {
+	id      string
+	version int
+}
+
+func (r record) Equal(other any) bool {
+	o, ok := other.(record)
+	return ok && r.id == o.id
+}
+
+func (r record) Key() string {
+	return r.id
+}
+
+func newest(old, new record) record {
+	if new.version > old.version {
+		return new
+	}
+	return old
+}
+
+// version returns the version of the record with the given id in s.
+func version(s Set[record], id string) int {
+	v := -1
+	s.Each(func(r record) bool {
+		if r.id == id {
+			v = r.version
+			return true
+		}
+		return false
+	})
+	return v
+}
+
+func testConflictPolicies(t *testing.T, newSet func(...record) Set[record]) {
+	r := require.New(t)
+
+	s := newSet(record{"a", 1}, record{"b", 1})
+	r.False(s.Add(record{"a", 2}))
+	r.Equal(2, version(s, "a"), "Add replaces the existing element")
+	r.False(s.AddWith(record{"a", 1}, KeepExisting[record]))
+	r.Equal(2, version(s, "a"))
+
+	r.False(s.AddWith(record{"a", 3}, newest))
+	r.Equal(3, version(s, "a"))
+	r.False(s.AddWith(record{"a", 2}, newest))
+	r.Equal(3, version(s, "a"))
+	r.True(s.AddWith(record{"c", 1}, newest))
+
+	old, ok := s.Replace(record{"b", 0})
+	r.True(ok)
+	r.Equal(record{"b", 1}, old)
+	r.Equal(0, version(s, "b"))
+	_, ok = s.Replace(record{"d", 1})
+	r.False(ok)
+	r.Equal(4, s.Cardinality())
+
+	other := newSet(record{"a", 5}, record{"b", 5}, record{"e", 1})
+	u := s.Union(other)
+	r.Equal(5, u.Cardinality())
+	r.Equal(5, version(u, "a"), "Union keeps the other set's elements")
+	r.Equal(5, version(u, "b"))
+
+	u = s.UnionWith(other, ReplaceExisting[record])
+	r.Equal(5, u.Cardinality())
+	r.Equal(5, version(u, "a"))
+	r.Equal(5, version(u, "b"))
+
+	u = s.UnionWith(other, KeepExisting[record])
+	r.Equal(3, version(u, "a"))
+	r.Equal(0, version(u, "b"))
+	r.Equal(3, version(s, "a"), "UnionWith does not modify the receiver")
+}
+
+func Test_ConflictPolicies(t *testing.T) {
+	runSafeUnsafe(t, NewSet[record], NewThreadUnsafeSet[record], testConflictPolicies)
+}
+
+func Test_OrderedConflictPolicies(t *testing.T) {
+	runSafeUnsafe(t,
+		func(vals ...record) Set[record] { return NewOrderedSet(vals...) },
+		func(vals ...record) Set[record] { return NewThreadUnsafeOrderedSet(vals...) },
+		testConflictPolicies)
+
+	r := require.New(t)
+	s := NewOrderedSet(record{"a", 1}, record{"b", 1})
+	s.Replace(record{"a", 2})
+	r.Equal([]record{{"a", 2}, {"b", 1}}, s.ToSlice(), "replacing keeps the position")
+}
+
+func Test_SortedConflictPolicies(t *testing.T) {
+	byVersion := func(a, b record) bool { return a.version < b.version }
+	runSafeUnsafe(t,
+		func(vals ...record) Set[record] { return NewSortedSet(byVersion, vals...) },
+		func(vals ...record) Set[record] { return NewThreadUnsafeSortedSet(byVersion, vals...) },
+		testConflictPolicies)
+
+	r := require.New(t)
+	s := NewSortedSet(byVersion, record{"a", 1}, record{"b", 2}, record{"c", 3})
+	s.Replace(record{"a", 4})
+	r.Equal([]record{{"b", 2}, {"c", 3}, {"a", 4}}, s.ToSlice(), "replacing reorders the set")
+	s.AddWith(record{"b", 2}, ReplaceExisting[record])
+	r.Equal([]record{{"b", 2}, {"c", 3}, {"a", 4}}, s.ToSlice())
+	min, _ := s.Min()
+	r.Equal(record{"b", 2}, min)
+}
+
+func Test_IdenticalConflictPolicies(t *testing.T) {
+	r := require.New(t)
+
+	c := NewComparableSet(1, 2)
+	old, ok := c.Replace(2)
+	r.True(ok)
+	r.Equal(2, old)
+	r.True(c.AddWith(3, KeepExisting[int]))
+	r.Equal(3, c.UnionWith(NewComparableSet(2), ReplaceExisting[int]).Cardinality())
+
+	b := NewBitSet[Int](1, 2)
+	_, ok = b.Replace(3)
+	r.False(ok)
+	r.False(b.AddWith(3, ReplaceExisting[Int]))
+	r.Equal(4, b.UnionWith(NewBitSet[Int](4), KeepExisting[Int]).Cardinality())
+}
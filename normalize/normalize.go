@@ -0,0 +1,140 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package normalize provides key normalisers for sets of strings which are
+// equal modulo case, surrounding space, Unicode normalisation or the
+// encoding of host names:
+//
+//	hosts := normalize.NewSet(normalize.Hostname)
+//	hosts.Add("Bücher.example")
+//	hosts.Contains("xn--bcher-kva.example.") // true
+//
+// Sets created by this package keep the spelling of the first inserted of
+// any equal strings.
+package normalize
+
+import (
+	"net/textproto"
+	"strings"
+
+	mapset "github.com/NectGmbH/golang-set/v3"
+	"golang.org/x/net/idna"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Func normalises a string into its key, strings with the same key are
+// equal. The funcs of this package can be combined with Chain.
+type Func func(string) string
+
+// Chain returns a Func applying fs in order.
+func Chain(fs ...Func) Func {
+	return func(s string) string {
+		for _, f := range fs {
+			s = f(s)
+		}
+		return s
+	}
+}
+
+// TrimSpace removes leading and trailing white space.
+func TrimSpace(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// FoldCase folds the case of s, so that strings which differ only in case
+// are equal. It uses Unicode full case folding, which is more thorough than
+// strings.ToLower: "Straße" and "STRASSE" are equal, for instance.
+func FoldCase(s string) string {
+	// Casers are stateful and must not be shared.
+	return cases.Fold().String(s)
+}
+
+// NFC normalises s to Unicode Normalization Form C, so that precomposed
+// and decomposed spellings of the same characters are equal.
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// NFKC normalises s to Unicode Normalization Form KC, which additionally
+// makes compatibility variants of characters, such as ligatures and full
+// width forms, equal to their plain counterparts.
+func NFKC(s string) string {
+	return norm.NFKC.String(s)
+}
+
+// CaseInsensitive folds the case of s and normalises it to NFC.
+func CaseInsensitive(s string) string {
+	return NFC(FoldCase(s))
+}
+
+// Hostname normalises a host name to its ASCII form as used in lookups,
+// encoding internationalised labels in punycode, lower casing the name and
+// dropping a trailing dot. Strings which are not valid host names are
+// merely case folded.
+func Hostname(s string) string {
+	s = strings.TrimSuffix(s, ".")
+	ascii, err := idna.Lookup.ToASCII(s)
+	if err != nil {
+		return CaseInsensitive(s)
+	}
+	return ascii
+}
+
+// Email normalises an e-mail address by treating its local part case
+// insensitively and normalising its domain with Hostname.
+func Email(s string) string {
+	at := strings.LastIndexByte(s, '@')
+	if at < 0 {
+		return CaseInsensitive(s)
+	}
+	return CaseInsensitive(s[:at]) + "@" + Hostname(s[at+1:])
+}
+
+// HeaderName normalises an HTTP or MIME header name to its canonical form,
+// such as "Content-Type".
+func HeaderName(s string) string {
+	return textproto.CanonicalMIMEHeaderKey(s)
+}
+
+// NewSet creates and returns a new set with the given strings, which are
+// equal if key normalises them to the same key. Operations on the resulting
+// set are thread-safe.
+func NewSet[T ~string](key Func, vals ...T) mapset.Set[T] {
+	return mapset.NewKeyedSetWith(keyOf[T](key), nil, mapset.KeepExisting[T], vals...)
+}
+
+// NewThreadUnsafeSet creates and returns a new set with the given strings,
+// which are equal if key normalises them to the same key. Operations on the
+// resulting set are not thread-safe.
+func NewThreadUnsafeSet[T ~string](key Func, vals ...T) mapset.Set[T] {
+	return mapset.NewThreadUnsafeKeyedSetWith(keyOf[T](key), nil, mapset.KeepExisting[T], vals...)
+}
+
+func keyOf[T ~string](key Func) func(T) string {
+	return func(v T) string {
+		return key(string(v))
+	}
+}
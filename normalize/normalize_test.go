@@ -0,0 +1,66 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Funcs(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("strasse", FoldCase("STRASSE"))
+	r.Equal(FoldCase("Straße"), FoldCase("STRASSE"))
+	r.Equal("\u00e9", NFC("e\u0301"))
+	r.Equal("fi", NFKC("ﬁ"))
+	r.Equal("xn--bcher-kva.example", Hostname("Bücher.EXAMPLE."))
+	r.Equal("xn--bcher-kva.example", Hostname("xn--bcher-kva.example"))
+	r.Equal("not a host!", Hostname("Not a Host!"))
+	r.Equal("ann.lee@xn--bcher-kva.example", Email("Ann.Lee@BÜCHER.example"))
+	r.Equal("no address", Email("No Address"))
+	r.Equal("Content-Type", HeaderName("content-TYPE"))
+	r.Equal("abc", Chain(TrimSpace, FoldCase)("  ABC\t"))
+}
+
+func Test_NewSet(t *testing.T) {
+	r := require.New(t)
+
+	hosts := NewSet(Hostname, "Bücher.example", "golang.org")
+	r.False(hosts.Add("xn--bcher-kva.example."))
+	r.True(hosts.Contains("GOLANG.org", "BÜCHER.EXAMPLE"))
+	r.ElementsMatch([]string{"Bücher.example", "golang.org"}, hosts.ToSlice(),
+		"the first inserted spelling is kept")
+
+	type header string
+	headers := NewThreadUnsafeSet[header](HeaderName, "content-type", "Content-Type", "ACCEPT")
+	r.Equal(2, headers.Cardinality())
+	r.True(headers.Contains("Accept"))
+
+	words := NewSet(Chain(TrimSpace, NFKC, CaseInsensitive), " Ｆile ", "file", "ﬁle")
+	r.Equal([]string{" Ｆile "}, words.ToSlice())
+}
@@ -0,0 +1,380 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"context"
+	"fmt"
+)
+
+// OrderedSet is a Set which remembers the order in which its elements were
+// first inserted. Iteration, ToSlice, String and MarshalJSON all yield the
+// elements in that order, and Pop removes the oldest element. Adding an
+// element which is already in the set does not change its position.
+type OrderedSet[T EqualKeyer] interface {
+	Set[T]
+
+	// Moves the given element to the back of the set,
+	// making it the newest element. Returns whether
+	// the element is in the set.
+	MoveToBack(v T) bool
+
+	// Moves the given element to the front of the set,
+	// making it the oldest element. Returns whether
+	// the element is in the set.
+	MoveToFront(v T) bool
+}
+
+// NewOrderedSet creates and returns a new insertion-ordered set with the
+// given elements. Operations on the resulting set are thread-safe.
+func NewOrderedSet[T EqualKeyer](vals ...T) OrderedSet[T] {
+	s := newOrderedSet[T]()
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return s.threadSafe().(OrderedSet[T])
+}
+
+// NewThreadUnsafeOrderedSet creates and returns a new insertion-ordered set
+// with the given elements. Operations on the resulting set are not
+// thread-safe.
+func NewThreadUnsafeOrderedSet[T EqualKeyer](vals ...T) OrderedSet[T] {
+	s := newOrderedSet[T]()
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return s
+}
+
+// orderedNode is an element of the doubly linked list backing orderedSet.
+type orderedNode[T EqualKeyer] struct {
+	elem       T
+	prev, next *orderedNode[T]
+}
+
+// orderedSet keeps its elements in a circular doubly linked list in
+// insertion order. Membership is resolved through an index of the list's
+// nodes by Key(), with the same bucket semantics as threadUnsafeSet.
+type orderedSet[T EqualKeyer] struct {
+	// root is the sentinel of the list, root.next is the oldest
+	// and root.prev the newest element.
+	root  orderedNode[T]
+	index map[string][]*orderedNode[T]
+	size  int
+}
+
+// Assert concrete type:orderedSet adheres to OrderedSet interface.
+var _ OrderedSet[String] = (*orderedSet[String])(nil)
+
+func newOrderedSet[T EqualKeyer]() *orderedSet[T] {
+	s := &orderedSet[T]{index: make(map[string][]*orderedNode[T])}
+	s.root.prev, s.root.next = &s.root, &s.root
+	return s
+}
+
+func (s *orderedSet[T]) emptySet() Set[T] {
+	return newOrderedSet[T]()
+}
+
+// replaceWith takes over the list of src, whose ends are relinked to the
+// sentinel of s.
+func (s *orderedSet[T]) replaceWith(src Set[T]) bool {
+	o, ok := src.(*orderedSet[T])
+	if !ok {
+		return false
+	}
+	s.Clear()
+	if o.size > 0 {
+		s.root.next, s.root.prev = o.root.next, o.root.prev
+		s.root.next.prev, s.root.prev.next = &s.root, &s.root
+	}
+	s.index, s.size = o.index, o.size
+	return true
+}
+
+func (s *orderedSet[T]) threadSafe() Set[T] {
+	ts := &threadSafeOrderedSet[T]{}
+	ts.init(s)
+	return ts
+}
+
+// find returns the key of v and the position of v's node within its
+// bucket of the index, the position is -1 when v is not in the set.
+func (s *orderedSet[T]) find(v T) (string, int) {
+	key := v.Key()
+	for i, node := range s.index[key] {
+		if node.elem.Equal(v) {
+			return key, i
+		}
+	}
+	return key, -1
+}
+
+// link inserts node into the list right after at.
+func (s *orderedSet[T]) link(node, at *orderedNode[T]) {
+	node.prev, node.next = at, at.next
+	at.next.prev = node
+	at.next = node
+}
+
+// unlink removes node from the list.
+func (s *orderedSet[T]) unlink(node *orderedNode[T]) {
+	node.prev.next = node.next
+	node.next.prev = node.prev
+	node.prev, node.next = nil, nil
+}
+
+// removeAt removes the node at position i of the index bucket stored under
+// key from the index as well as from the list.
+func (s *orderedSet[T]) removeAt(key string, i int) T {
+	bucket := s.index[key]
+	node := bucket[i]
+	if len(bucket) == 1 {
+		delete(s.index, key)
+	} else {
+		s.index[key] = append(bucket[:i:i], bucket[i+1:]...)
+	}
+	s.unlink(node)
+	s.size--
+	return node.elem
+}
+
+func (s *orderedSet[T]) Add(v T) bool {
+	return s.AddWith(v, ReplaceExisting[T])
+}
+
+// AddWith keeps the position of an Equal element already in the set when
+// replacing it by the result of merge.
+func (s *orderedSet[T]) AddWith(v T, merge MergeFunc[T]) bool {
+	key, i := s.find(v)
+	if i >= 0 {
+		node := s.index[key][i]
+		node.elem = merge(node.elem, v)
+		return false
+	}
+	node := &orderedNode[T]{elem: v}
+	s.link(node, s.root.prev)
+	s.index[key] = append(s.index[key], node)
+	s.size++
+	return true
+}
+
+func (s *orderedSet[T]) checkedAdd(v T, unique bool) (bool, error) {
+	if key, i := s.find(v); i < 0 && unique && len(s.index[key]) > 0 {
+		return false, fmt.Errorf("%w: %v collides with %v", ErrKeyCollision, v, s.index[key][0].elem)
+	}
+	return s.Add(v), nil
+}
+
+func (s *orderedSet[T]) Replace(v T) (T, bool) {
+	return replace[T](s, v)
+}
+
+func (s *orderedSet[T]) Cardinality() int {
+	return s.size
+}
+
+func (s *orderedSet[T]) Clear() {
+	s.index = make(map[string][]*orderedNode[T])
+	s.root.prev, s.root.next = &s.root, &s.root
+	s.size = 0
+}
+
+func (s *orderedSet[T]) Clone() Set[T] {
+	return addAll[T](newOrderedSet[T](), s)
+}
+
+func (s *orderedSet[T]) Contains(v ...T) bool {
+	for _, val := range v {
+		if _, i := s.find(val); i < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *orderedSet[T]) Difference(other Set[T]) Set[T] {
+	return difference[T](newOrderedSet[T](), s, other)
+}
+
+func (s *orderedSet[T]) Each(cb func(T) bool) {
+	for node := s.root.next; node != &s.root; node = node.next {
+		if cb(node.elem) {
+			return
+		}
+	}
+}
+
+func (s *orderedSet[T]) Equal(other Set[T]) bool {
+	return equal[T](s, other)
+}
+
+// Intersect returns the elements of s which are also in other, in the
+// order of s.
+func (s *orderedSet[T]) Intersect(other Set[T]) Set[T] {
+	intersection := newOrderedSet[T]()
+	s.Each(func(elem T) bool {
+		if other.Contains(elem) {
+			intersection.Add(elem)
+		}
+		return false
+	})
+	return intersection
+}
+
+func (s *orderedSet[T]) IsProperSubset(other Set[T]) bool {
+	return s.IsSubset(other) && !s.Equal(other)
+}
+
+func (s *orderedSet[T]) IsProperSuperset(other Set[T]) bool {
+	return s.IsSuperset(other) && !s.Equal(other)
+}
+
+func (s *orderedSet[T]) IsSubset(other Set[T]) bool {
+	return isSubset[T](s, other)
+}
+
+func (s *orderedSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s *orderedSet[T]) Iter() <-chan T {
+	return iterChan[T](s)
+}
+
+func (s *orderedSet[T]) Iterator() *Iterator[T] {
+	return iterator[T](s)
+}
+
+func (s *orderedSet[T]) Pull(ctx context.Context) *PullIterator[T] {
+	return newPullIterator(ctx, s.ToSlice())
+}
+
+func (s *orderedSet[T]) All() func(yield func(T) bool) {
+	return all[T](s)
+}
+
+// Pop removes and returns the oldest element of the set.
+func (s *orderedSet[T]) Pop() (v T, ok bool) {
+	if s.size == 0 {
+		return
+	}
+	v = s.root.next.elem
+	s.Remove(v)
+	return v, true
+}
+
+func (s *orderedSet[T]) Remove(v T) {
+	if key, i := s.find(v); i >= 0 {
+		s.removeAt(key, i)
+	}
+}
+
+func (s *orderedSet[T]) String() string {
+	return toString[T](s)
+}
+
+func (s *orderedSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	return symmetricDifference[T](newOrderedSet[T](), s, other)
+}
+
+func (s *orderedSet[T]) ToSlice() []T {
+	return toSlice[T](s)
+}
+
+func (s *orderedSet[T]) Union(other Set[T]) Set[T] {
+	return addAll(s.Clone(), other)
+}
+
+func (s *orderedSet[T]) UnionWith(other Set[T], merge MergeFunc[T]) Set[T] {
+	return unionWith(s.Clone(), other, merge)
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+// in insertion order.
+func (s *orderedSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T](s)
+}
+
+// UnmarshalJSON recreates a set from a JSON array, it only decodes
+// primitive types. Numbers are decoded as json.Number. The elements are
+// added in the order in which they appear in the array.
+func (s *orderedSet[T]) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON[T](s, b)
+}
+
+// MarshalText creates a comma separated list of the elements of the set.
+func (s *orderedSet[T]) MarshalText() ([]byte, error) {
+	return marshalText[T](s)
+}
+
+// UnmarshalText recreates a set from a comma separated list.
+func (s *orderedSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText[T](s, text)
+}
+
+func (s *orderedSet[T]) MoveToBack(v T) bool {
+	key, i := s.find(v)
+	if i < 0 {
+		return false
+	}
+	node := s.index[key][i]
+	s.unlink(node)
+	s.link(node, s.root.prev)
+	return true
+}
+
+func (s *orderedSet[T]) MoveToFront(v T) bool {
+	key, i := s.find(v)
+	if i < 0 {
+		return false
+	}
+	node := s.index[key][i]
+	s.unlink(node)
+	s.link(node, &s.root)
+	return true
+}
+
+// threadSafeOrderedSet guards an orderedSet with a sync.RWMutex.
+type threadSafeOrderedSet[T EqualKeyer] struct {
+	threadSafeSet[T]
+}
+
+func (s *threadSafeOrderedSet[T]) ordered() *orderedSet[T] {
+	return s.uss.(*orderedSet[T])
+}
+
+func (s *threadSafeOrderedSet[T]) MoveToBack(v T) bool {
+	s.lock()
+	defer s.Unlock()
+	return s.ordered().MoveToBack(v)
+}
+
+func (s *threadSafeOrderedSet[T]) MoveToFront(v T) bool {
+	s.lock()
+	defer s.Unlock()
+	return s.ordered().MoveToFront(v)
+}
@@ -0,0 +1,105 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OrderedSetOrder(t *testing.T) {
+	runSafeUnsafe(t, NewOrderedSet[String], NewThreadUnsafeOrderedSet[String], func(t *testing.T, newSet func(...String) OrderedSet[String]) {
+		r := require.New(t)
+		s := newSet("eu", "us", "ap", "us", "sa")
+
+		r.Equal(4, s.Cardinality())
+		r.Equal([]String{"eu", "us", "ap", "sa"}, s.ToSlice())
+		r.Equal("Set{eu, us, ap, sa}", s.String())
+
+		b, err := json.Marshal(s)
+		r.NoError(err)
+		r.Equal(`["eu","us","ap","sa"]`, string(b))
+
+		var visited []String
+		for elem := range s.Iter() {
+			visited = append(visited, elem)
+		}
+		r.Equal([]String{"eu", "us", "ap", "sa"}, visited)
+
+		visited = nil
+		it := s.Iterator()
+		for elem := range it.C {
+			visited = append(visited, elem)
+		}
+		r.Equal([]String{"eu", "us", "ap", "sa"}, visited)
+
+		r.False(s.Add("eu"), "re-adding an element should not change its position")
+		r.Equal([]String{"eu", "us", "ap", "sa"}, s.ToSlice())
+
+		r.True(s.MoveToBack("eu"))
+		r.True(s.MoveToFront("sa"))
+		r.False(s.MoveToFront("af"))
+		r.Equal([]String{"sa", "us", "ap", "eu"}, s.ToSlice())
+
+		v, ok := s.Pop()
+		r.True(ok)
+		r.Equal(String("sa"), v, "Pop should remove the oldest element")
+		s.Remove("ap")
+		r.Equal([]String{"us", "eu"}, s.ToSlice())
+
+		s.Clear()
+		r.Zero(s.Cardinality())
+		_, ok = s.Pop()
+		r.False(ok)
+		s.Add("af")
+		r.Equal([]String{"af"}, s.ToSlice())
+	})
+}
+
+func Test_OrderedSetAlgebra(t *testing.T) {
+	r := require.New(t)
+	a := NewOrderedSet[String]("c", "a", "d", "b")
+	b := NewThreadUnsafeSet[String]("b", "e", "c")
+
+	u, ok := a.Union(b).(OrderedSet[String])
+	r.True(ok, "Union of an ordered set should return an ordered set")
+	r.Equal([]String{"c", "a", "d", "b", "e"}, u.ToSlice())
+
+	r.Equal([]String{"c", "b"}, a.Intersect(b).ToSlice())
+	r.Equal([]String{"a", "d"}, a.Difference(b).ToSlice())
+	r.Equal([]String{"a", "d", "e"}, a.SymmetricDifference(b).ToSlice())
+	r.Equal([]String{"c", "a", "d", "b"}, a.Clone().ToSlice())
+}
+
+func Test_OrderedSetUnmarshalJSON(t *testing.T) {
+	r := require.New(t)
+	s := NewOrderedSet[String]()
+
+	r.NoError(json.Unmarshal([]byte(`["z", "x", "y", "x"]`), s))
+	r.Equal([]String{"z", "x", "y"}, s.ToSlice())
+}
@@ -0,0 +1,103 @@
+//go:build go1.23
+
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"iter"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _ iter.Seq[String] = NewSet[String]().All()
+
+func Test_All(t *testing.T) {
+	r := require.New(t)
+
+	for _, a := range []Set[String]{
+		NewSet[String]("Z", "Y", "X", "W"),
+		NewThreadUnsafeSet[String]("Z", "Y", "X", "W"),
+		NewComparableSet[String]("Z", "Y", "X", "W"),
+	} {
+		b := NewThreadUnsafeSet[String]()
+		for v := range a.All() {
+			b.Add(v)
+		}
+		assertEqual(a, b, r)
+
+		n := 0
+		for range a.All() {
+			n++
+			break
+		}
+		r.Equal(1, n)
+	}
+
+	var elems []String
+	for v := range NewOrderedSet[String]("Z", "Y", "X").All() {
+		elems = append(elems, v)
+	}
+	r.Equal([]String{"Z", "Y", "X"}, elems)
+}
+
+func Test_AllMutatingSafe(t *testing.T) {
+	r := require.New(t)
+
+	a := NewSet[Int](1, 2, 3)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for v := range a.All() {
+			a.Remove(v)
+			a.Add(v + 10)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		r.FailNow("mutating the set within the loop deadlocked")
+	}
+	assertEqual(a, NewSet[Int](11, 12, 13), r)
+}
+
+func Test_Collect(t *testing.T) {
+	r := require.New(t)
+
+	s := Collect(slices.Values([]String{"a", "b", "a"}))
+	assertEqual(s, NewSet[String]("a", "b"), r)
+
+	r.Equal(1, AddSeq(s, slices.Values([]String{"b", "c"})))
+	assertEqual(s, NewSet[String]("a", "b", "c"), r)
+
+	u := NewThreadUnsafeSet[String]()
+	r.Equal(3, AddSeq(u, s.All()))
+	assertEqual(s, u, r)
+}
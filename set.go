@@ -28,27 +28,66 @@ SOFTWARE.
 // typical set operations: membership testing, intersection, union,
 // difference, symmetric difference and cloning.
 //
-// Package mapset provides two implementations of the Set
-// interface. The default implementation is safe for concurrent
-// access, but a non-thread-safe implementation is also provided for
+// Package mapset provides two flavors of every implementation of
+// the Set interface. The default flavor is safe for concurrent
+// access, but a non-thread-safe flavor is also provided for
 // programs that can benefit from the slight speed improvement and
 // that can enforce mutual exclusion through other means.
+//
+// Elements of sets created by NewSet must implement EqualKeyer.
+// Elements of any comparable type can be stored in sets created by
+// NewComparableSet instead, without the need for wrapper types, and
+// elements of any type at all in sets created by NewKeyedSet, which
+// takes the key and equal funcs to use for them.
 package mapset
 
-// Comparable
-type EqualKeyer interface {
+import "context"
+
+// Keyer is implemented by elements which are identified by a key of
+// type K. Key must return the same key for elements which are Equal,
+// elements with different keys are never considered equal.
+//
+// Sets of Keyer elements are strict: two elements are the same element of
+// a set if and only if they have the same key and Equal reports true. Keys
+// merely locate candidates, so elements which share a key but are not
+// Equal are distinct elements of the set. Add, Remove, Contains, Pop and
+// all set algebra follow this rule alike; only the KeyIndex methods address
+// elements by key alone. Equal must be symmetric.
+type Keyer[K comparable] interface {
 	Equal(to any) bool
-	Key() string
+	Key() K
+}
+
+// EqualKeyer is a Keyer with string keys, it is the element constraint
+// of sets created by NewSet.
+type EqualKeyer interface {
+	Keyer[string]
 }
 
 // Set is the primary interface provided by the mapset package.  It
 // represents an unordered set of data and a large number of
 // operations that can be applied to that set.
-type Set[T EqualKeyer] interface {
+//
+// The binary operations accept any implementation of Set as their
+// argument, so thread-safe and thread-unsafe sets can be mixed freely.
+// Sets returned by them use the same implementation as the receiver.
+type Set[T any] interface {
 	// Adds an element to the set. Returns whether
-	// the item was added.
+	// the item was added. If an Equal element is
+	// already in the set, it is replaced by val, unless
+	// the set was created with NewKeyedSetWith.
 	Add(val T) bool
 
+	// Adds an element to the set like Add, but if an
+	// Equal element is already in the set, it is
+	// replaced by the result of merge.
+	AddWith(val T, merge MergeFunc[T]) bool
+
+	// Adds an element to the set, replacing an Equal
+	// element already in the set. Returns the replaced
+	// element and whether there was one.
+	Replace(val T) (T, bool)
+
 	// Returns the number of elements in the set.
 	Cardinality() int
 
@@ -68,11 +107,6 @@ type Set[T EqualKeyer] interface {
 	// and other. The returned set will contain
 	// all elements of this set that are not also
 	// elements of other.
-	//
-	// Note that the argument to Difference
-	// must be of the same type as the receiver
-	// of the method. Otherwise, Difference will
-	// panic.
 	Difference(other Set[T]) Set[T]
 
 	// Determines if two sets are equal to each
@@ -80,56 +114,27 @@ type Set[T EqualKeyer] interface {
 	// and contain the same elements, they are
 	// considered equal. The order in which
 	// the elements were added is irrelevant.
-	//
-	// Note that the argument to Equal must be
-	// of the same type as the receiver of the
-	// method. Otherwise, Equal will panic.
 	Equal(other Set[T]) bool
 
 	// Returns a new set containing only the elements
 	// that exist only in both sets.
-	//
-	// Note that the argument to Intersect
-	// must be of the same type as the receiver
-	// of the method. Otherwise, Intersect will
-	// panic.
 	Intersect(other Set[T]) Set[T]
 
 	// Determines if every element in this set is in
 	// the other set but the two sets are not equal.
-	//
-	// Note that the argument to IsProperSubset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsProperSubset
-	// will panic.
 	IsProperSubset(other Set[T]) bool
 
 	// Determines if every element in the other set
 	// is in this set but the two sets are not
 	// equal.
-	//
-	// Note that the argument to IsSuperset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsSuperset will
-	// panic.
 	IsProperSuperset(other Set[T]) bool
 
 	// Determines if every element in this set is in
 	// the other set.
-	//
-	// Note that the argument to IsSubset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsSubset will
-	// panic.
 	IsSubset(other Set[T]) bool
 
 	// Determines if every element in the other set
 	// is in this set.
-	//
-	// Note that the argument to IsSuperset
-	// must be of the same type as the receiver
-	// of the method. Otherwise, IsSuperset will
-	// panic.
 	IsSuperset(other Set[T]) bool
 
 	// Iterates over elements and executes the passed func against each element.
@@ -144,6 +149,14 @@ type Set[T EqualKeyer] interface {
 	// use to range over the set.
 	Iterator() *Iterator[T]
 
+	// Returns a PullIterator over a snapshot of the set
+	// that stops early once ctx is done.
+	Pull(ctx context.Context) *PullIterator[T]
+
+	// Returns a func that yields the elements of the set,
+	// it can be used as an iter.Seq with range-over-func.
+	All() func(yield func(T) bool)
+
 	// Remove a single element from the set.
 	Remove(i T)
 
@@ -153,20 +166,18 @@ type Set[T EqualKeyer] interface {
 
 	// Returns a new set with all elements which are
 	// in either this set or the other set but not in both.
-	//
-	// Note that the argument to SymmetricDifference
-	// must be of the same type as the receiver
-	// of the method. Otherwise, SymmetricDifference
-	// will panic.
 	SymmetricDifference(other Set[T]) Set[T]
 
 	// Returns a new set with all elements in both sets.
-	//
-	// Note that the argument to Union must be of the
-	// same type as the receiver of the method.
-	// Otherwise, IsSuperset will panic.
+	// Of Equal elements, the one of the other set is kept,
+	// unless the set was created with NewKeyedSetWith.
 	Union(other Set[T]) Set[T]
 
+	// Returns a new set with all elements in both sets.
+	// Equal elements are replaced by the result of
+	// merge, called with the element of this set first.
+	UnionWith(other Set[T], merge MergeFunc[T]) Set[T]
+
 	// Pop removes and returns an arbitrary item from the set.
 	Pop() (T, bool)
 
@@ -176,27 +187,113 @@ type Set[T EqualKeyer] interface {
 	// MarshalJSON will marshal the set into a JSON-based representation.
 	MarshalJSON() ([]byte, error)
 
-	// UnmarshalJSON will unmarshal a JSON-based byte slice into a full Set datastructure.
-	// For this to work, set subtypes must implemented the Marshal/Unmarshal interface.
+	// UnmarshalJSON will unmarshal a JSON-based byte slice into a full Set datastructure,
+	// replacing its previous elements. For this to work, set subtypes must implemented
+	// the Marshal/Unmarshal interface. Use the UnmarshalJSON func for other options.
 	UnmarshalJSON(b []byte) error
+
+	// MarshalText will marshal the set into a comma separated list of its elements.
+	// Elements containing commas, quotes or line breaks are quoted as in CSV.
+	MarshalText() ([]byte, error)
+
+	// UnmarshalText will replace the elements of the set with those of a comma
+	// separated list as created by MarshalText.
+	UnmarshalText(text []byte) error
 }
 
 // NewSet creates and returns a new set with the given elements.
 // Operations on the resulting set are thread-safe.
 func NewSet[T EqualKeyer](vals ...T) Set[T] {
-	s := newThreadSafeSet[T]()
+	s := newThreadUnsafeKeyerSet[string, T]()
 	for _, item := range vals {
 		s.Add(item)
 	}
-	return &s
+	return newThreadSafeSet[T](&s)
 }
 
 // NewThreadUnsafeSet creates and returns a new set with the given elements.
 // Operations on the resulting set are not thread-safe.
 func NewThreadUnsafeSet[T EqualKeyer](vals ...T) Set[T] {
-	s := newThreadUnsafeSet[T]()
+	s := newThreadUnsafeKeyerSet[string, T]()
 	for _, item := range vals {
 		s.Add(item)
 	}
 	return &s
 }
+
+// NewKeyerSet creates and returns a new set with the given elements, which
+// are identified by keys of type K. Numeric or composite keys avoid the
+// allocations needed to build string keys for EqualKeyer elements.
+// Operations on the resulting set are thread-safe.
+func NewKeyerSet[K comparable, T Keyer[K]](vals ...T) Set[T] {
+	s := newThreadUnsafeKeyerSet[K, T]()
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return newThreadSafeSet[T](&s)
+}
+
+// NewThreadUnsafeKeyerSet creates and returns a new set with the given
+// elements, which are identified by keys of type K. Numeric or composite
+// keys avoid the allocations needed to build string keys for EqualKeyer
+// elements. Operations on the resulting set are not thread-safe.
+func NewThreadUnsafeKeyerSet[K comparable, T Keyer[K]](vals ...T) Set[T] {
+	s := newThreadUnsafeKeyerSet[K, T]()
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return &s
+}
+
+// NewKeyedSet creates and returns a new set with the given elements, which
+// may be of any type. Elements are identified by the keys key returns and
+// told apart by equal, following the same rules as Keyer elements: equal
+// must report true only for elements with the same key. If equal is nil,
+// elements with the same key are equal. Operations on the resulting set
+// are thread-safe.
+func NewKeyedSet[T any](key func(T) string, equal func(a, b T) bool, vals ...T) Set[T] {
+	s := newThreadUnsafeKeyedSet(key, equal, nil, vals)
+	return newThreadSafeSet[T](&s)
+}
+
+// NewKeyedSetWith creates and returns a new set like NewKeyedSet, but Add
+// and Union resolve conflicts between Equal elements with merge instead of
+// replacing the element already in the set. This includes Equal elements
+// among vals. Sets derived from the set, such as clones and unions, use
+// merge as well. Operations on the resulting set are thread-safe.
+func NewKeyedSetWith[T any](key func(T) string, equal func(a, b T) bool, merge MergeFunc[T], vals ...T) Set[T] {
+	s := newThreadUnsafeKeyedSet(key, equal, merge, vals)
+	return newThreadSafeSet[T](&s)
+}
+
+// NewThreadUnsafeKeyedSet creates and returns a new set with the given
+// elements, which may be of any type. Elements are identified by the keys
+// key returns and told apart by equal, following the same rules as Keyer
+// elements: equal must report true only for elements with the same key.
+// If equal is nil, elements with the same key are equal. Operations on the
+// resulting set are not thread-safe.
+func NewThreadUnsafeKeyedSet[T any](key func(T) string, equal func(a, b T) bool, vals ...T) Set[T] {
+	s := newThreadUnsafeKeyedSet(key, equal, nil, vals)
+	return &s
+}
+
+// NewThreadUnsafeKeyedSetWith creates and returns a new set like
+// NewThreadUnsafeKeyedSet, but Add and Union resolve conflicts between
+// Equal elements with merge, as described for NewKeyedSetWith. Operations
+// on the resulting set are not thread-safe.
+func NewThreadUnsafeKeyedSetWith[T any](key func(T) string, equal func(a, b T) bool, merge MergeFunc[T], vals ...T) Set[T] {
+	s := newThreadUnsafeKeyedSet(key, equal, merge, vals)
+	return &s
+}
+
+func newThreadUnsafeKeyedSet[T any](key func(T) string, equal func(a, b T) bool, merge MergeFunc[T], vals []T) threadUnsafeSet[string, T] {
+	if equal == nil {
+		equal = func(a, b T) bool { return true }
+	}
+	s := newThreadUnsafeSet(key, equal)
+	s.merge = merge
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return s
+}
@@ -26,6 +26,8 @@ SOFTWARE.
 package mapset
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 	"testing"
 
@@ -47,6 +49,39 @@ func (i Int) Key() string {
 	return strconv.Itoa(int(i))
 }
 
+// KeyedInt is identified by an int key rather than a string one.
+type KeyedInt int
+
+func (i KeyedInt) Equal(jAny any) bool {
+	j, ok := jAny.(KeyedInt)
+	if !ok {
+		return false
+	}
+
+	return i == j
+}
+
+func (i KeyedInt) Key() int {
+	return int(i)
+}
+
+// hashed mimics an element keyed by a truncated hash: distinct values
+// share keys whenever they are congruent modulo 3.
+type hashed int
+
+func (i hashed) Equal(jAny any) bool {
+	j, ok := jAny.(hashed)
+	if !ok {
+		return false
+	}
+
+	return i == j
+}
+
+func (i hashed) Key() string {
+	return strconv.Itoa(int(i) % 3)
+}
+
 func makeSetInt(ints []Int) Set[Int] {
 	s := NewSet[Int]()
 	for _, i := range ints {
@@ -63,10 +98,17 @@ func makeUnsafeSetInt(ints []Int) Set[Int] {
 	return s
 }
 
-func assertEqual[T EqualKeyer](a, b Set[T], r *require.Assertions) {
+func assertEqual[T any](a, b Set[T], r *require.Assertions) {
 	r.Truef(a.Equal(b), "Expected no difference, got: %v", a.Difference(b))
 }
 
+// runSafeUnsafe runs test as the subtests "safe" and "unsafe", passing it
+// the thread-safe and the thread-unsafe constructor of a set respectively.
+func runSafeUnsafe[F any](t *testing.T, safe, unsafe F, test func(t *testing.T, newSet F)) {
+	t.Run("safe", func(t *testing.T) { test(t, safe) })
+	t.Run("unsafe", func(t *testing.T) { test(t, unsafe) })
+}
+
 func Test_NewSet(t *testing.T) {
 	r := require.New(t)
 	a := NewSet[Int]()
@@ -959,6 +1001,77 @@ func Test_IteratorStop(t *testing.T) {
 	}
 }
 
+func Test_IteratorStopTwice(t *testing.T) {
+	it := NewSet[String]("Z", "Y").Iterator()
+	it.Stop()
+	it.Stop()
+}
+
+func Test_Pull(t *testing.T) {
+	r := require.New(t)
+
+	for _, a := range []Set[String]{
+		NewSet[String]("Z", "Y", "X", "W"),
+		NewThreadUnsafeSet[String]("Z", "Y", "X", "W"),
+	} {
+		b := NewSet[String]()
+		it := a.Pull(context.Background())
+		for it.Next() {
+			b.Add(it.Value())
+		}
+		r.NoError(it.Err())
+		r.False(it.Next())
+		r.Equal(String(""), it.Value())
+		assertEqual(a, b, r)
+	}
+}
+
+func Test_PullSnapshot(t *testing.T) {
+	r := require.New(t)
+
+	a := NewOrderedSet[String]("Z", "Y")
+	it := a.Pull(context.Background())
+
+	// Writers are not blocked by the iterator, nor do they affect it.
+	a.Add("X")
+	a.Remove("Z")
+
+	var elems []String
+	for it.Next() {
+		elems = append(elems, it.Value())
+	}
+	r.Equal([]String{"Z", "Y"}, elems)
+}
+
+func Test_PullClose(t *testing.T) {
+	r := require.New(t)
+
+	a := NewSet[String]("Z", "Y", "X")
+	it := a.Pull(context.Background())
+	r.True(it.Next())
+	it.Close()
+	it.Close()
+	r.False(it.Next())
+	r.NoError(it.Err())
+
+	// An abandoned iterator holds no read lock.
+	_ = a.Pull(context.Background())
+	a.Add("W")
+	r.Equal(4, a.Cardinality())
+}
+
+func Test_PullContext(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := NewSet[String]("Z", "Y", "X").Pull(ctx)
+	r.True(it.Next())
+	cancel()
+	r.False(it.Next())
+	r.ErrorIs(it.Err(), context.Canceled)
+	r.False(it.Next())
+}
+
 func Test_PopSafe(t *testing.T) {
 	a := NewSet[String]()
 
@@ -1088,6 +1201,156 @@ func Test_ToSliceUnthreadsafe(t *testing.T) {
 	}
 }
 
+func testKeyCollision(t *testing.T, newSet func(...hashed) Set[hashed]) {
+	r := require.New(t)
+
+	a := newSet(0, 1, 2, 3, 4, 5)
+	r.Equal(6, a.Cardinality(), "colliding elements must not overwrite each other")
+	r.True(a.Contains(0, 1, 2, 3, 4, 5))
+	r.False(a.Contains(6), "6 shares a key with 0 and 3 but is not in the set")
+	r.False(a.Add(3), "3 is already in the set")
+	r.Equal(6, a.Cardinality())
+
+	a.Remove(6)
+	r.Equal(6, a.Cardinality(), "removing an absent colliding element must not remove anything")
+	a.Remove(3)
+	r.Equal(5, a.Cardinality())
+	r.False(a.Contains(3))
+	r.True(a.Contains(0, 1, 2, 4, 5))
+
+	b := newSet(0, 6, 9, 4)
+	assertEqual(a.Union(b), newSet(0, 1, 2, 4, 5, 6, 9), r)
+	assertEqual(a.Intersect(b), newSet(0, 4), r)
+	assertEqual(b.Intersect(a), newSet(0, 4), r)
+	assertEqual(a.Difference(b), newSet(1, 2, 5), r)
+	assertEqual(a.SymmetricDifference(b), newSet(1, 2, 5, 6, 9), r)
+	r.True(newSet(0, 4).IsSubset(a))
+	r.False(newSet(0, 6).IsSubset(a))
+
+	popped := newSet()
+	for a.Cardinality() > 0 {
+		v, ok := a.Pop()
+		r.True(ok)
+		r.True(popped.Add(v), "Pop returned %v twice", v)
+	}
+	assertEqual(popped, newSet(0, 1, 2, 4, 5), r)
+}
+
+func Test_KeyCollisionSet(t *testing.T) {
+	runSafeUnsafe(t, NewSet[hashed], NewThreadUnsafeSet[hashed], testKeyCollision)
+}
+
+func Test_KeyCollisionOrderedSet(t *testing.T) {
+	runSafeUnsafe(t,
+		func(vals ...hashed) Set[hashed] { return NewOrderedSet(vals...) },
+		func(vals ...hashed) Set[hashed] { return NewThreadUnsafeOrderedSet(vals...) },
+		testKeyCollision)
+}
+
+func Test_KeyCollisionSortedSet(t *testing.T) {
+	// Elements colliding in key are equivalent under less as well.
+	byKey := func(a, b hashed) bool { return a%3 < b%3 }
+	runSafeUnsafe(t,
+		func(vals ...hashed) Set[hashed] { return NewSortedSet(byKey, vals...) },
+		func(vals ...hashed) Set[hashed] { return NewThreadUnsafeSortedSet(byKey, vals...) },
+		testKeyCollision)
+}
+
+func Test_KeyCollisionStrict(t *testing.T) {
+	r := require.New(t)
+
+	impls := map[string]func(...hashed) Set[hashed]{
+		"safe":    NewSet[hashed],
+		"unsafe":  NewThreadUnsafeSet[hashed],
+		"ordered": func(vals ...hashed) Set[hashed] { return NewOrderedSet(vals...) },
+		"sorted": func(vals ...hashed) Set[hashed] {
+			return NewThreadUnsafeSortedSet(func(a, b hashed) bool { return a < b }, vals...)
+		},
+	}
+	for nameA, newA := range impls {
+		for nameB, newB := range impls {
+			// All elements share key "0" but none is Equal to another
+			// of the other set.
+			a := newA(0, 3)
+			b := newB(6, 9)
+			msg := nameA + " vs " + nameB
+
+			r.False(a.Equal(b), msg)
+			r.False(a.IsSubset(b), msg)
+			r.False(b.IsSuperset(a), msg)
+			r.Equal(4, a.Union(b).Cardinality(), msg)
+			r.Zero(a.Intersect(b).Cardinality(), msg)
+			assertEqual(a.Difference(b), newA(0, 3), r)
+			assertEqual(a.SymmetricDifference(b), newA(0, 3, 6, 9), r)
+
+			r.True(a.Add(6), msg)
+			r.True(a.IsProperSuperset(newB(6, 0)), msg)
+			a.Remove(9)
+			r.Equal(3, a.Cardinality(), msg)
+			_, ok := a.Replace(9)
+			r.False(ok, msg)
+			r.Equal(4, a.Cardinality(), msg)
+			r.True(a.Equal(b.Union(newB(0, 3))), msg)
+		}
+	}
+}
+
+func testMixedImplementations(t *testing.T, newA, newB func(...Int) Set[Int]) {
+	r := require.New(t)
+
+	a := newA(1, 2, 3, 4)
+	b := newB(3, 4, 5)
+	implA := fmt.Sprintf("%T", a)
+
+	results := map[string]Set[Int]{
+		"Union":               a.Union(b),
+		"Intersect":           a.Intersect(b),
+		"Difference":          a.Difference(b),
+		"SymmetricDifference": a.SymmetricDifference(b),
+	}
+	for op, res := range results {
+		r.Equalf(implA, fmt.Sprintf("%T", res), "%s should return the receiver's implementation", op)
+	}
+
+	assertEqual(results["Union"], newA(1, 2, 3, 4, 5), r)
+	assertEqual(results["Intersect"], newA(3, 4), r)
+	assertEqual(results["Difference"], newA(1, 2), r)
+	assertEqual(results["SymmetricDifference"], newA(1, 2, 5), r)
+
+	r.True(a.Equal(newB(4, 3, 2, 1)))
+	r.False(a.Equal(b))
+	r.True(newB(3, 4).IsSubset(a))
+	r.True(newB(3, 4).IsProperSubset(a))
+	r.True(a.IsSuperset(newB(1, 2)))
+	r.True(a.IsProperSuperset(newB(1, 2)))
+	r.False(a.IsSubset(b))
+	r.False(b.IsSuperset(a))
+}
+
+func Test_MixedImplementations(t *testing.T) {
+	t.Run("safe-unsafe", func(t *testing.T) {
+		testMixedImplementations(t, NewSet[Int], NewThreadUnsafeSet[Int])
+	})
+	t.Run("unsafe-safe", func(t *testing.T) {
+		testMixedImplementations(t, NewThreadUnsafeSet[Int], NewSet[Int])
+	})
+}
+
+func Test_KeyerSet(t *testing.T) {
+	runSafeUnsafe(t, NewKeyerSet[int, KeyedInt], NewThreadUnsafeKeyerSet[int, KeyedInt], func(t *testing.T, newSet func(...KeyedInt) Set[KeyedInt]) {
+		r := require.New(t)
+		s := newSet(1, 2, 3, 2)
+
+		r.Equal(3, s.Cardinality())
+		r.True(s.Contains(1, 2, 3))
+		r.False(s.Contains(4))
+		s.Remove(2)
+		assertEqual(s, newSet(1, 3), r)
+		assertEqual(s.Union(newSet(4)), newSet(1, 3, 4), r)
+		assertEqual(s.Intersect(newSet(3, 4)), newSet(3), r)
+	})
+}
+
 func Test_Example(t *testing.T) {
 	/*
 	   requiredClasses := NewSet()
@@ -0,0 +1,649 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"context"
+	"fmt"
+)
+
+// SortedSet is a Set whose elements are kept in the order defined by a
+// less function. Iteration, ToSlice, String and MarshalJSON all yield
+// the elements in ascending order, and the set additionally supports
+// order-based queries.
+//
+// Elements which are equivalent under less, i.e. neither is less than the
+// other, but which are not Equal are distinct elements of the set. They are
+// ordered by insertion.
+//
+// The fields of elements which less depends on must not be modified while
+// the elements are in the set, e.g. through pointers, as the order and the
+// queries of the set are undefined afterwards. Removing such an element
+// still works, but rebuilds the tree of the set.
+type SortedSet[T EqualKeyer] interface {
+	Set[T]
+
+	// Returns the smallest element of the set.
+	Min() (T, bool)
+
+	// Returns the largest element of the set.
+	Max() (T, bool)
+
+	// Returns the largest element of the set which is
+	// less than or equivalent to v.
+	Floor(v T) (T, bool)
+
+	// Returns the smallest element of the set which is
+	// greater than or equivalent to v.
+	Ceiling(v T) (T, bool)
+
+	// Returns the elements of the set which are greater
+	// than or equivalent to from and less than to, in
+	// ascending order.
+	Range(from, to T) []T
+
+	// Returns the number of elements of the set which are
+	// less than v.
+	Rank(v T) int
+
+	// Returns the element at position i of the set in
+	// ascending order, starting at 0.
+	Select(i int) (T, bool)
+}
+
+// NewSortedSet creates and returns a new sorted set with the given
+// elements, ordered by less. Operations on the resulting set are
+// thread-safe.
+func NewSortedSet[T EqualKeyer](less func(a, b T) bool, vals ...T) SortedSet[T] {
+	s := newSortedSet(less)
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return s.threadSafe().(SortedSet[T])
+}
+
+// NewThreadUnsafeSortedSet creates and returns a new sorted set with the
+// given elements, ordered by less. Operations on the resulting set are
+// not thread-safe.
+func NewThreadUnsafeSortedSet[T EqualKeyer](less func(a, b T) bool, vals ...T) SortedSet[T] {
+	s := newSortedSet(less)
+	for _, item := range vals {
+		s.Add(item)
+	}
+	return s
+}
+
+// sortedNode is a node of the AVL tree backing sortedSet.
+type sortedNode[T EqualKeyer] struct {
+	elem        T
+	seq         uint64
+	left, right *sortedNode[T]
+	height      int
+	size        int
+}
+
+func (n *sortedNode[T]) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *sortedNode[T]) getSize() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *sortedNode[T]) update() {
+	n.height = 1 + n.left.getHeight()
+	if h := n.right.getHeight(); h >= n.height {
+		n.height = 1 + h
+	}
+	n.size = 1 + n.left.getSize() + n.right.getSize()
+}
+
+func (n *sortedNode[T]) rotateLeft() *sortedNode[T] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+func (n *sortedNode[T]) rotateRight() *sortedNode[T] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func (n *sortedNode[T]) rebalance() *sortedNode[T] {
+	n.update()
+	switch balance := n.left.getHeight() - n.right.getHeight(); {
+	case balance > 1:
+		if n.left.left.getHeight() < n.left.right.getHeight() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case balance < -1:
+		if n.right.right.getHeight() < n.right.left.getHeight() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+// sortedSet keeps its elements in an AVL tree ordered by less and, for
+// equivalent elements, by insertion. Membership is resolved through an
+// index of the tree's nodes by Key(), with the same bucket semantics as
+// threadUnsafeSet.
+type sortedSet[T EqualKeyer] struct {
+	less  func(a, b T) bool
+	root  *sortedNode[T]
+	index map[string][]*sortedNode[T]
+	seq   uint64
+}
+
+// Assert concrete type:sortedSet adheres to SortedSet interface.
+var _ SortedSet[String] = (*sortedSet[String])(nil)
+
+func newSortedSet[T EqualKeyer](less func(a, b T) bool) *sortedSet[T] {
+	return &sortedSet[T]{
+		less:  less,
+		index: make(map[string][]*sortedNode[T]),
+	}
+}
+
+func (s *sortedSet[T]) threadSafe() Set[T] {
+	ts := &threadSafeSortedSet[T]{}
+	ts.init(s)
+	return ts
+}
+
+func (s *sortedSet[T]) empty() *sortedSet[T] {
+	return newSortedSet(s.less)
+}
+
+func (s *sortedSet[T]) emptySet() Set[T] {
+	return s.empty()
+}
+
+func (s *sortedSet[T]) replaceWith(src Set[T]) bool {
+	o, ok := src.(*sortedSet[T])
+	if ok {
+		*s = *o
+	}
+	return ok
+}
+
+// before reports whether node a is ordered before node b.
+func (s *sortedSet[T]) before(a, b *sortedNode[T]) bool {
+	if s.less(a.elem, b.elem) {
+		return true
+	}
+	return !s.less(b.elem, a.elem) && a.seq < b.seq
+}
+
+func (s *sortedSet[T]) insert(n, node *sortedNode[T]) *sortedNode[T] {
+	if n == nil {
+		return node
+	}
+	if s.before(node, n) {
+		n.left = s.insert(n.left, node)
+	} else {
+		n.right = s.insert(n.right, node)
+	}
+	return n.rebalance()
+}
+
+// removeMin detaches the smallest node from the subtree rooted at n, it
+// returns the new root of the subtree along with the detached node.
+func (s *sortedSet[T]) removeMin(n *sortedNode[T]) (*sortedNode[T], *sortedNode[T]) {
+	if n.left == nil {
+		return n.right, n
+	}
+	var m *sortedNode[T]
+	n.left, m = s.removeMin(n.left)
+	return n.rebalance(), m
+}
+
+// delete removes node from the subtree rooted at n, locating it by its
+// order. It returns the new root of the subtree and whether node was found,
+// which it is not if the ordering of its element changed in the meantime.
+func (s *sortedSet[T]) delete(n, node *sortedNode[T]) (*sortedNode[T], bool) {
+	var found bool
+	switch {
+	case n == nil:
+		return nil, false
+	case n == node:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+		right, m := s.removeMin(n.right)
+		m.left, m.right = n.left, right
+		return m.rebalance(), true
+	case s.before(node, n):
+		n.left, found = s.delete(n.left, node)
+	default:
+		n.right, found = s.delete(n.right, node)
+	}
+	return n.rebalance(), found
+}
+
+// unlink removes node from the tree. If node cannot be located by its order,
+// as elements were modified such that they are ordered differently than when
+// they were inserted, the tree is rebuilt from its remaining nodes instead.
+func (s *sortedSet[T]) unlink(node *sortedNode[T]) {
+	root, found := s.delete(s.root, node)
+	if found {
+		s.root = root
+		return
+	}
+
+	var nodes []*sortedNode[T]
+	s.walkNodes(s.root, func(n *sortedNode[T]) {
+		if n != node {
+			nodes = append(nodes, n)
+		}
+	})
+	s.root = nil
+	for _, n := range nodes {
+		n.left, n.right, n.height, n.size = nil, nil, 1, 1
+		s.root = s.insert(s.root, n)
+	}
+}
+
+// walkNodes calls cb for the nodes of the subtree rooted at n in order.
+func (s *sortedSet[T]) walkNodes(n *sortedNode[T], cb func(*sortedNode[T])) {
+	if n == nil {
+		return
+	}
+	s.walkNodes(n.left, cb)
+	cb(n)
+	s.walkNodes(n.right, cb)
+}
+
+// find returns the key of v and the position of v's node within its
+// bucket of the index, the position is -1 when v is not in the set.
+func (s *sortedSet[T]) find(v T) (string, int) {
+	key := v.Key()
+	for i, node := range s.index[key] {
+		if node.elem.Equal(v) {
+			return key, i
+		}
+	}
+	return key, -1
+}
+
+// removeAt removes the node at position i of the index bucket stored under
+// key from the index as well as from the tree.
+func (s *sortedSet[T]) removeAt(key string, i int) T {
+	bucket := s.index[key]
+	node := bucket[i]
+	if len(bucket) == 1 {
+		delete(s.index, key)
+	} else {
+		s.index[key] = append(bucket[:i:i], bucket[i+1:]...)
+	}
+	s.unlink(node)
+	return node.elem
+}
+
+// walk calls cb for the elements of the subtree rooted at n in ascending
+// order until cb returns true. It reports whether cb stopped the walk.
+func (s *sortedSet[T]) walk(n *sortedNode[T], cb func(T) bool) bool {
+	if n == nil {
+		return false
+	}
+	return s.walk(n.left, cb) || cb(n.elem) || s.walk(n.right, cb)
+}
+
+func (s *sortedSet[T]) Add(v T) bool {
+	return s.AddWith(v, ReplaceExisting[T])
+}
+
+// AddWith moves an Equal element already in the set to the position of the
+// result of merge, if it is ordered differently. Among equivalent elements
+// it keeps its position.
+func (s *sortedSet[T]) AddWith(v T, merge MergeFunc[T]) bool {
+	key, i := s.find(v)
+	if i >= 0 {
+		node := s.index[key][i]
+		merged := merge(node.elem, v)
+		if !s.less(merged, node.elem) && !s.less(node.elem, merged) {
+			node.elem = merged
+			return false
+		}
+		s.unlink(node)
+		node.elem = merged
+		node.left, node.right, node.height, node.size = nil, nil, 1, 1
+		s.root = s.insert(s.root, node)
+		return false
+	}
+	s.seq++
+	node := &sortedNode[T]{elem: v, seq: s.seq, height: 1, size: 1}
+	s.root = s.insert(s.root, node)
+	s.index[key] = append(s.index[key], node)
+	return true
+}
+
+func (s *sortedSet[T]) checkedAdd(v T, unique bool) (bool, error) {
+	if key, i := s.find(v); i < 0 && unique && len(s.index[key]) > 0 {
+		return false, fmt.Errorf("%w: %v collides with %v", ErrKeyCollision, v, s.index[key][0].elem)
+	}
+	return s.Add(v), nil
+}
+
+func (s *sortedSet[T]) Cardinality() int {
+	return s.root.getSize()
+}
+
+func (s *sortedSet[T]) Clear() {
+	*s = *s.empty()
+}
+
+func (s *sortedSet[T]) Clone() Set[T] {
+	return addAll[T](s.empty(), s)
+}
+
+func (s *sortedSet[T]) Contains(v ...T) bool {
+	for _, val := range v {
+		if _, i := s.find(val); i < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *sortedSet[T]) Difference(other Set[T]) Set[T] {
+	return difference[T](s.empty(), s, other)
+}
+
+func (s *sortedSet[T]) Each(cb func(T) bool) {
+	s.walk(s.root, cb)
+}
+
+func (s *sortedSet[T]) Equal(other Set[T]) bool {
+	return equal[T](s, other)
+}
+
+func (s *sortedSet[T]) Intersect(other Set[T]) Set[T] {
+	return intersect[T](s.empty(), s, other)
+}
+
+func (s *sortedSet[T]) IsProperSubset(other Set[T]) bool {
+	return s.IsSubset(other) && !s.Equal(other)
+}
+
+func (s *sortedSet[T]) IsProperSuperset(other Set[T]) bool {
+	return s.IsSuperset(other) && !s.Equal(other)
+}
+
+func (s *sortedSet[T]) IsSubset(other Set[T]) bool {
+	return isSubset[T](s, other)
+}
+
+func (s *sortedSet[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s *sortedSet[T]) Iter() <-chan T {
+	return iterChan[T](s)
+}
+
+func (s *sortedSet[T]) Iterator() *Iterator[T] {
+	return iterator[T](s)
+}
+
+func (s *sortedSet[T]) Pull(ctx context.Context) *PullIterator[T] {
+	return newPullIterator(ctx, s.ToSlice())
+}
+
+func (s *sortedSet[T]) All() func(yield func(T) bool) {
+	return all[T](s)
+}
+
+// Pop removes and returns the smallest element of the set.
+func (s *sortedSet[T]) Pop() (v T, ok bool) {
+	if v, ok = s.Min(); ok {
+		s.Remove(v)
+	}
+	return
+}
+
+func (s *sortedSet[T]) Remove(v T) {
+	if key, i := s.find(v); i >= 0 {
+		s.removeAt(key, i)
+	}
+}
+
+func (s *sortedSet[T]) String() string {
+	return toString[T](s)
+}
+
+func (s *sortedSet[T]) SymmetricDifference(other Set[T]) Set[T] {
+	return symmetricDifference[T](s.empty(), s, other)
+}
+
+func (s *sortedSet[T]) ToSlice() []T {
+	return toSlice[T](s)
+}
+
+func (s *sortedSet[T]) Union(other Set[T]) Set[T] {
+	return addAll(s.Clone(), other)
+}
+
+func (s *sortedSet[T]) UnionWith(other Set[T], merge MergeFunc[T]) Set[T] {
+	return unionWith(s.Clone(), other, merge)
+}
+
+func (s *sortedSet[T]) Replace(v T) (T, bool) {
+	return replace[T](s, v)
+}
+
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+// in ascending order.
+func (s *sortedSet[T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T](s)
+}
+
+// UnmarshalJSON recreates a set from a JSON array, it only decodes
+// primitive types. Numbers are decoded as json.Number.
+func (s *sortedSet[T]) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON[T](s, b)
+}
+
+// MarshalText creates a comma separated list of the elements of the set.
+func (s *sortedSet[T]) MarshalText() ([]byte, error) {
+	return marshalText[T](s)
+}
+
+// UnmarshalText recreates a set from a comma separated list.
+func (s *sortedSet[T]) UnmarshalText(text []byte) error {
+	return unmarshalText[T](s, text)
+}
+
+func (s *sortedSet[T]) Min() (v T, ok bool) {
+	n := s.root
+	if n == nil {
+		return
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.elem, true
+}
+
+func (s *sortedSet[T]) Max() (v T, ok bool) {
+	n := s.root
+	if n == nil {
+		return
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.elem, true
+}
+
+func (s *sortedSet[T]) Floor(v T) (floor T, ok bool) {
+	for n := s.root; n != nil; {
+		if s.less(v, n.elem) {
+			n = n.left
+		} else {
+			floor, ok = n.elem, true
+			n = n.right
+		}
+	}
+	return
+}
+
+func (s *sortedSet[T]) Ceiling(v T) (ceiling T, ok bool) {
+	for n := s.root; n != nil; {
+		if s.less(n.elem, v) {
+			n = n.right
+		} else {
+			ceiling, ok = n.elem, true
+			n = n.left
+		}
+	}
+	return
+}
+
+func (s *sortedSet[T]) Range(from, to T) []T {
+	var elems []T
+	s.rangeOf(s.root, from, to, &elems)
+	return elems
+}
+
+// rangeOf appends the elements of the subtree rooted at n which lie within
+// [from, to) to elems, skipping subtrees that lie outside of it.
+func (s *sortedSet[T]) rangeOf(n *sortedNode[T], from, to T, elems *[]T) {
+	if n == nil {
+		return
+	}
+	aboveFrom := !s.less(n.elem, from)
+	belowTo := s.less(n.elem, to)
+	if aboveFrom {
+		s.rangeOf(n.left, from, to, elems)
+	}
+	if aboveFrom && belowTo {
+		*elems = append(*elems, n.elem)
+	}
+	if belowTo {
+		s.rangeOf(n.right, from, to, elems)
+	}
+}
+
+func (s *sortedSet[T]) Rank(v T) int {
+	rank := 0
+	for n := s.root; n != nil; {
+		if s.less(n.elem, v) {
+			rank += n.left.getSize() + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return rank
+}
+
+func (s *sortedSet[T]) Select(i int) (v T, ok bool) {
+	if i < 0 {
+		return
+	}
+	for n := s.root; n != nil; {
+		switch left := n.left.getSize(); {
+		case i < left:
+			n = n.left
+		case i == left:
+			return n.elem, true
+		default:
+			i -= left + 1
+			n = n.right
+		}
+	}
+	return
+}
+
+// threadSafeSortedSet guards a sortedSet with a sync.RWMutex.
+type threadSafeSortedSet[T EqualKeyer] struct {
+	threadSafeSet[T]
+}
+
+func (s *threadSafeSortedSet[T]) sorted() *sortedSet[T] {
+	return s.uss.(*sortedSet[T])
+}
+
+func (s *threadSafeSortedSet[T]) Min() (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Min()
+}
+
+func (s *threadSafeSortedSet[T]) Max() (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Max()
+}
+
+func (s *threadSafeSortedSet[T]) Floor(v T) (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Floor(v)
+}
+
+func (s *threadSafeSortedSet[T]) Ceiling(v T) (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Ceiling(v)
+}
+
+func (s *threadSafeSortedSet[T]) Range(from, to T) []T {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Range(from, to)
+}
+
+func (s *threadSafeSortedSet[T]) Rank(v T) int {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Rank(v)
+}
+
+func (s *threadSafeSortedSet[T]) Select(i int) (T, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.sorted().Select(i)
+}
@@ -0,0 +1,227 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func lessInt(a, b Int) bool {
+	return a < b
+}
+
+func Test_SortedSetOrder(t *testing.T) {
+	runSafeUnsafe(t, NewSortedSet[Int], NewThreadUnsafeSortedSet[Int], func(t *testing.T, newSet func(func(a, b Int) bool, ...Int) SortedSet[Int]) {
+		r := require.New(t)
+		s := newSet(lessInt, 5, 3, 9, 1, 7, 3)
+
+		r.Equal(5, s.Cardinality())
+		r.Equal([]Int{1, 3, 5, 7, 9}, s.ToSlice())
+		r.Equal("Set{1, 3, 5, 7, 9}", s.String())
+
+		b, err := json.Marshal(s)
+		r.NoError(err)
+		r.Equal(`[1,3,5,7,9]`, string(b))
+
+		var visited []Int
+		s.Each(func(elem Int) bool {
+			visited = append(visited, elem)
+			return elem == 5
+		})
+		r.Equal([]Int{1, 3, 5}, visited)
+
+		visited = nil
+		for elem := range s.Iter() {
+			visited = append(visited, elem)
+		}
+		r.Equal([]Int{1, 3, 5, 7, 9}, visited)
+
+		u, ok := s.Union(NewThreadUnsafeSet[Int](4, 8)).(SortedSet[Int])
+		r.True(ok, "Union of a sorted set should return a sorted set")
+		r.Equal([]Int{1, 3, 4, 5, 7, 8, 9}, u.ToSlice())
+		c, ok := s.Clone().(SortedSet[Int])
+		r.True(ok, "Clone of a sorted set should return a sorted set")
+		r.True(c.Equal(s))
+
+		v, ok := s.Pop()
+		r.True(ok)
+		r.Equal(Int(1), v)
+		r.Equal([]Int{3, 5, 7, 9}, s.ToSlice())
+	})
+}
+
+func Test_SortedSetQueries(t *testing.T) {
+	runSafeUnsafe(t, NewSortedSet[Int], NewThreadUnsafeSortedSet[Int], func(t *testing.T, newSet func(func(a, b Int) bool, ...Int) SortedSet[Int]) {
+		r := require.New(t)
+		s := newSet(lessInt, 10, 20, 30, 40)
+
+		v, ok := s.Min()
+		r.True(ok)
+		r.Equal(Int(10), v)
+		v, ok = s.Max()
+		r.True(ok)
+		r.Equal(Int(40), v)
+
+		v, ok = s.Floor(25)
+		r.True(ok)
+		r.Equal(Int(20), v)
+		v, ok = s.Floor(20)
+		r.True(ok)
+		r.Equal(Int(20), v)
+		_, ok = s.Floor(5)
+		r.False(ok)
+
+		v, ok = s.Ceiling(25)
+		r.True(ok)
+		r.Equal(Int(30), v)
+		v, ok = s.Ceiling(30)
+		r.True(ok)
+		r.Equal(Int(30), v)
+		_, ok = s.Ceiling(45)
+		r.False(ok)
+
+		r.Equal([]Int{20, 30}, s.Range(20, 40))
+		r.Equal([]Int{10, 20, 30, 40}, s.Range(0, 50))
+		r.Empty(s.Range(21, 30))
+
+		r.Equal(0, s.Rank(10))
+		r.Equal(2, s.Rank(25))
+		r.Equal(4, s.Rank(50))
+
+		v, ok = s.Select(2)
+		r.True(ok)
+		r.Equal(Int(30), v)
+		_, ok = s.Select(4)
+		r.False(ok)
+		_, ok = s.Select(-1)
+		r.False(ok)
+
+		empty := newSet(lessInt)
+		_, ok = empty.Min()
+		r.False(ok)
+		_, ok = empty.Max()
+		r.False(ok)
+		_, ok = empty.Pop()
+		r.False(ok)
+	})
+}
+
+func Test_SortedSetRandomized(t *testing.T) {
+	r := require.New(t)
+	s := NewThreadUnsafeSortedSet(lessInt)
+	ref := make(map[Int]bool)
+
+	for i := 0; i < 5000; i++ {
+		v := Int(rand.Intn(500))
+		if rand.Intn(3) == 0 {
+			s.Remove(v)
+			delete(ref, v)
+		} else {
+			s.Add(v)
+			ref[v] = true
+		}
+	}
+
+	expected := make([]int, 0, len(ref))
+	for v := range ref {
+		expected = append(expected, int(v))
+	}
+	sort.Ints(expected)
+
+	r.Equal(len(expected), s.Cardinality())
+	for i, v := range expected {
+		elem, ok := s.Select(i)
+		r.True(ok)
+		r.Equal(Int(v), elem)
+		r.Equal(i, s.Rank(Int(v)))
+	}
+}
+
+func Test_SortedSetEquivalentElements(t *testing.T) {
+	r := require.New(t)
+	byKey := func(a, b hashed) bool {
+		return a.Key() < b.Key()
+	}
+	s := NewThreadUnsafeSortedSet(byKey, 4, 0, 3, 1)
+
+	r.Equal(4, s.Cardinality(), "equivalent elements which are not Equal are distinct")
+	r.Equal([]hashed{0, 3, 4, 1}, s.ToSlice(), "equivalent elements should be ordered by insertion")
+	r.False(s.Contains(6))
+
+	s.Remove(3)
+	r.Equal([]hashed{0, 4, 1}, s.ToSlice())
+	r.Equal([]hashed{0}, s.Range(0, 1))
+}
+
+// ranked is stored by pointer, identified by its name and ordered by its
+// rank, which may be changed in place.
+type ranked struct {
+	name string
+	rank int
+}
+
+func (x *ranked) Equal(other any) bool {
+	o, ok := other.(*ranked)
+	return ok && x.name == o.name
+}
+
+func (x *ranked) Key() string {
+	return x.name
+}
+
+func Test_SortedSetRemoveReordered(t *testing.T) {
+	r := require.New(t)
+	byRank := func(a, b *ranked) bool {
+		return a.rank < b.rank
+	}
+	s := NewThreadUnsafeSortedSet(byRank)
+	elems := make([]*ranked, 20)
+	for i := range elems {
+		elems[i] = &ranked{name: strconv.Itoa(i), rank: i}
+		s.Add(elems[i])
+	}
+
+	elems[3].rank = 100
+	elems[10].rank = -1
+	s.Remove(elems[3])
+	r.False(s.Contains(elems[3]))
+	r.Equal(19, s.Cardinality())
+
+	v, ok := s.Min()
+	r.True(ok)
+	r.Same(elems[10], v, "the tree should be rebuilt in the current order")
+	for i := 0; i < s.Cardinality(); i++ {
+		v, ok := s.Select(i)
+		r.True(ok)
+		r.Equal(i, s.Rank(v))
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+var errScanNilSet = fmt.Errorf("%w: cannot scan into a nil Set", ErrIncompatibleSet)
+
+// JSONColumn adapts a Set for storage in a JSON column, it implements
+// sql.Scanner and driver.Valuer on top of MarshalJSON and UnmarshalJSON.
+//
+// A nil Set is stored as NULL, scanning NULL leaves the Set empty.
+type JSONColumn[T any] struct {
+	Set Set[T]
+}
+
+// Value returns the JSON encoding of the set.
+func (c JSONColumn[T]) Value() (driver.Value, error) {
+	if c.Set == nil {
+		return nil, nil
+	}
+	b, err := c.Set.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan replaces the elements of the set with those of the JSON array src.
+// If src cannot be decoded, the set is left unchanged.
+func (c JSONColumn[T]) Scan(src any) error {
+	if c.Set == nil {
+		return errScanNilSet
+	}
+
+	b, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	decoded := emptyClone(c.Set)
+	if b != nil {
+		if err := decoded.UnmarshalJSON(b); err != nil {
+			return err
+		}
+	}
+	replaceAll(c.Set, decoded)
+	return nil
+}
+
+// ArrayColumn adapts a Set for storage in a PostgreSQL array column such as
+// text[], it implements sql.Scanner and driver.Valuer using the array
+// literal format {a,b,"c d"}.
+//
+// A nil Set is stored as NULL, scanning NULL leaves the Set empty. NULL
+// elements cannot be scanned into a set.
+type ArrayColumn[T any] struct {
+	Set Set[T]
+
+	// Parse converts an element of the array literal to T. If Parse is
+	// nil, elements whose pointer type implements
+	// encoding.TextUnmarshaler are parsed with UnmarshalText, elements
+	// of an underlying string type are taken verbatim and all others are
+	// decoded as JSON.
+	Parse func(string) (T, error)
+}
+
+// Value returns the set as an array literal. Elements implementing
+// encoding.TextMarshaler are formatted with MarshalText, all others as by
+// fmt.Sprint.
+func (c ArrayColumn[T]) Value() (driver.Value, error) {
+	if c.Set == nil {
+		return nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	var err error
+	first := true
+	c.Set.Each(func(elem T) bool {
+		var text string
+		if text, err = formatElement(elem); err != nil {
+			return true
+		}
+		if !first {
+			sb.WriteByte(',')
+		}
+		first = false
+		writeArrayElement(&sb, text)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
+
+// Scan replaces the elements of the set with those of the array literal src.
+// If src cannot be parsed, the set is left unchanged.
+func (c ArrayColumn[T]) Scan(src any) error {
+	if c.Set == nil {
+		return errScanNilSet
+	}
+
+	b, err := scanBytes(src)
+	if err != nil {
+		return err
+	}
+	decoded := emptyClone(c.Set)
+	if b != nil {
+		if err := c.parseInto(decoded, string(b)); err != nil {
+			return err
+		}
+	}
+	replaceAll(c.Set, decoded)
+	return nil
+}
+
+// parseInto adds the elements of the array literal s to dst.
+func (c ArrayColumn[T]) parseInto(dst Set[T], s string) error {
+	elems, err := parseArray(s)
+	if err != nil {
+		return err
+	}
+	parse := c.Parse
+	if parse == nil {
+		parse = parseElement[T]
+	}
+	for _, text := range elems {
+		v, err := parse(text)
+		if err != nil {
+			return err
+		}
+		if _, err := addChecked(dst, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanBytes returns the contents of a column value, nil for NULL.
+func scanBytes(src any) ([]byte, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return src, nil
+	case string:
+		return []byte(src), nil
+	default:
+		return nil, fmt.Errorf("mapset: cannot scan %T into a set", src)
+	}
+}
+
+// writeArrayElement writes text to sb as an element of an array literal,
+// quoting it if necessary.
+func writeArrayElement(sb *strings.Builder, text string) {
+	if text != "" && !strings.EqualFold(text, "NULL") && !strings.ContainsAny(text, "{}\",\\ \t\n\r\v\f") {
+		sb.WriteString(text)
+		return
+	}
+
+	sb.WriteByte('"')
+	for i := 0; i < len(text); i++ {
+		if text[i] == '"' || text[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(text[i])
+	}
+	sb.WriteByte('"')
+}
+
+// parseArray splits a one-dimensional array literal into its elements.
+func parseArray(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("mapset: malformed array literal %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var elems []string
+	for i := 0; ; {
+		for i < len(s) && isArraySpace(s[i]) {
+			i++
+		}
+
+		var elem strings.Builder
+		if i < len(s) && s[i] == '"' {
+			for i++; ; i++ {
+				if i >= len(s) {
+					return nil, fmt.Errorf("mapset: unterminated quoted element in array literal")
+				}
+				if s[i] == '"' {
+					i++
+					break
+				}
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				elem.WriteByte(s[i])
+			}
+			for i < len(s) && isArraySpace(s[i]) {
+				i++
+			}
+		} else {
+			start := i
+			for i < len(s) && s[i] != ',' {
+				switch s[i] {
+				case '{', '}', '"':
+					return nil, fmt.Errorf("mapset: unexpected %q in array literal, only one-dimensional arrays are supported", s[i])
+				}
+				i++
+			}
+			text := strings.TrimRight(s[start:i], " \t\n\r\v\f")
+			if text == "" {
+				return nil, fmt.Errorf("mapset: empty element in array literal")
+			}
+			if strings.EqualFold(text, "NULL") {
+				return nil, fmt.Errorf("mapset: NULL element in array literal")
+			}
+			elem.WriteString(text)
+		}
+		elems = append(elems, elem.String())
+
+		if i == len(s) {
+			return elems, nil
+		}
+		if s[i] != ',' {
+			return nil, fmt.Errorf("mapset: unexpected %q in array literal", s[i])
+		}
+		i++
+	}
+}
+
+func isArraySpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
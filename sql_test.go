@@ -0,0 +1,133 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ sql.Scanner   = JSONColumn[String]{}
+	_ driver.Valuer = JSONColumn[String]{}
+	_ sql.Scanner   = ArrayColumn[String]{}
+	_ driver.Valuer = ArrayColumn[String]{}
+)
+
+func Test_JSONColumn(t *testing.T) {
+	r := require.New(t)
+
+	v, err := JSONColumn[String]{NewOrderedSet[String]("a", "b")}.Value()
+	r.NoError(err)
+	r.Equal(`["a","b"]`, v)
+
+	v, err = JSONColumn[String]{}.Value()
+	r.NoError(err)
+	r.Nil(v)
+
+	s := NewSet[String]("stale")
+	r.NoError(JSONColumn[String]{s}.Scan([]byte(`["a","b"]`)))
+	assertEqual(s, NewSet[String]("a", "b"), r)
+	r.NoError(JSONColumn[String]{s}.Scan(`["c"]`))
+	assertEqual(s, NewSet[String]("c"), r)
+	r.NoError(JSONColumn[String]{s}.Scan(nil))
+	r.Zero(s.Cardinality())
+
+	s.Add("kept")
+	r.Error(JSONColumn[String]{s}.Scan(42))
+	r.Error(JSONColumn[String]{s}.Scan(`["x", 1]`))
+	r.Error(JSONColumn[String]{s}.Scan(`["x"`))
+	assertEqual(s, NewSet[String]("kept"), r)
+	r.Error(JSONColumn[String]{}.Scan(`[]`))
+}
+
+func Test_ArrayColumnValue(t *testing.T) {
+	r := require.New(t)
+
+	v, err := ArrayColumn[String]{Set: NewOrderedSet[String]("a", "c d", `e"f`, `g\h`, "", "null", "{i}", "j,k")}.Value()
+	r.NoError(err)
+	r.Equal(`{a,"c d","e\"f","g\\h","","null","{i}","j,k"}`, v)
+
+	v, err = ArrayColumn[Int]{Set: NewOrderedSet[Int](1, 2)}.Value()
+	r.NoError(err)
+	r.Equal(`{1,2}`, v)
+
+	v, err = ArrayColumn[String]{Set: NewSet[String]()}.Value()
+	r.NoError(err)
+	r.Equal(`{}`, v)
+
+	v, err = ArrayColumn[String]{}.Value()
+	r.NoError(err)
+	r.Nil(v)
+}
+
+func Test_ArrayColumnScan(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]("stale")
+	r.NoError(ArrayColumn[String]{Set: s}.Scan([]byte(`{a, "c d" ,"e\"f","g\\h","",b c}`)))
+	assertEqual(s, NewSet[String]("a", "c d", `e"f`, `g\h`, "", "b c"), r)
+
+	r.NoError(ArrayColumn[String]{Set: s}.Scan(`{}`))
+	r.Zero(s.Cardinality())
+
+	s.Add("stale")
+	r.NoError(ArrayColumn[String]{Set: s}.Scan(nil))
+	r.Zero(s.Cardinality())
+
+	ints := NewSet[Int]()
+	r.NoError(ArrayColumn[Int]{Set: ints}.Scan(`{1,2,3}`))
+	assertEqual(ints, NewSet[Int](1, 2, 3), r)
+
+	keyed := NewSet[Int]()
+	parse := func(s string) (Int, error) {
+		i, err := strconv.Atoi(s)
+		return Int(i * 10), err
+	}
+	r.NoError(ArrayColumn[Int]{Set: keyed, Parse: parse}.Scan(`{1,2}`))
+	assertEqual(keyed, NewSet[Int](10, 20), r)
+
+	for _, malformed := range []string{
+		`a,b`,
+		`{a,NULL}`,
+		`{a,}`,
+		`{"a}`,
+		`{"a"b}`,
+		`{{a},{b}}`,
+	} {
+		kept := NewSet[String]("kept")
+		r.Errorf(ArrayColumn[String]{Set: kept}.Scan(malformed), "expected an error scanning %s", malformed)
+		assertEqual(kept, NewSet[String]("kept"), r)
+	}
+	r.Error(ArrayColumn[Int]{Set: ints}.Scan(`{4,a}`))
+	assertEqual(ints, NewSet[Int](1, 2, 3), r)
+	r.Error(ArrayColumn[String]{Set: s}.Scan(1.5))
+	r.Error(ArrayColumn[String]{}.Scan(`{}`))
+}
@@ -0,0 +1,222 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// textFormat describes how sets are represented as text: a list of
+// elements delimited by a separator. If quoting is enabled, elements
+// containing the separator, quotes, line breaks or leading spaces are
+// enclosed in double quotes, with quotes inside doubled as in CSV.
+type textFormat[T any] struct {
+	separator rune
+	noQuotes  bool
+	parse     func(string) (T, error)
+	format    func(T) (string, error)
+}
+
+// defaultTextFormat is the format of MarshalText and UnmarshalText: comma
+// separated, quoted elements formatted by formatElement and parsed by
+// parseElement.
+func defaultTextFormat[T any]() textFormat[T] {
+	return textFormat[T]{
+		separator: ',',
+		parse:     parseElement[T],
+		format:    formatElement[T],
+	}
+}
+
+// join returns the textual representation of elems.
+func (f textFormat[T]) join(elems []string) ([]byte, error) {
+	if f.noQuotes {
+		return []byte(strings.Join(elems, string(f.separator))), nil
+	}
+	if len(elems) == 1 && elems[0] == "" {
+		// csv.Writer would write an empty line, which reads back as no
+		// elements at all.
+		return []byte(`""`), nil
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = f.separator
+	if err := w.Write(elems); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// split returns the elements of the textual representation text.
+func (f textFormat[T]) split(text string) ([]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+	if f.noQuotes {
+		return strings.Split(text, string(f.separator)), nil
+	}
+
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = f.separator
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var elems []string
+	for _, record := range records {
+		elems = append(elems, record...)
+	}
+	return elems, nil
+}
+
+// marshal returns the textual representation of the elements of s.
+func (f textFormat[T]) marshal(s Set[T]) ([]byte, error) {
+	elems := make([]string, 0, s.Cardinality())
+	var err error
+	s.Each(func(elem T) bool {
+		var text string
+		if text, err = f.format(elem); err != nil {
+			return true
+		}
+		elems = append(elems, text)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f.join(elems)
+}
+
+// parseAll parses the elements of the textual representation text.
+func (f textFormat[T]) parseAll(text []byte) ([]T, error) {
+	elems, err := f.split(string(text))
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]T, 0, len(elems))
+	for _, elem := range elems {
+		v, err := f.parse(elem)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+// unmarshal adds the elements of the textual representation text to s. If
+// text cannot be parsed, s is left unchanged.
+func (f textFormat[T]) unmarshal(s Set[T], text []byte) error {
+	vals, err := f.parseAll(text)
+	if err != nil {
+		return err
+	}
+	decoded := emptyClone(s)
+	for _, v := range vals {
+		if _, err := addChecked(decoded, v); err != nil {
+			return err
+		}
+	}
+	addAll(s, decoded)
+	return nil
+}
+
+// replace replaces the elements of s with those of the textual
+// representation text. If text cannot be parsed, s is left unchanged.
+func (f textFormat[T]) replace(s Set[T], text []byte) error {
+	vals, err := f.parseAll(text)
+	if err != nil {
+		return err
+	}
+	decoded := emptyClone(s)
+	for _, v := range vals {
+		if _, err := addChecked(decoded, v); err != nil {
+			return err
+		}
+	}
+	replaceAll(s, decoded)
+	return nil
+}
+
+// marshalText encodes the set as comma separated list of its elements.
+func marshalText[T any](s Set[T]) ([]byte, error) {
+	return defaultTextFormat[T]().marshal(s)
+}
+
+// unmarshalText replaces the elements of the set with those of a comma
+// separated list.
+func unmarshalText[T any](s Set[T], text []byte) error {
+	return defaultTextFormat[T]().replace(s, text)
+}
+
+// formatElement returns the textual representation of v. Elements
+// implementing encoding.TextMarshaler are formatted with MarshalText, all
+// others as by fmt.Sprint.
+func formatElement[T any](v T) (string, error) {
+	if m, ok := any(v).(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	return fmt.Sprint(v), nil
+}
+
+// parseElement converts the textual representation s to an element of
+// type T. If *T implements encoding.TextUnmarshaler, UnmarshalText is used.
+// Otherwise types with an underlying string type take s verbatim and all
+// other types are decoded from s as JSON, so that numbers and booleans are
+// parsed as usual.
+func parseElement[T any](s string) (T, error) {
+	var v T
+	if u, ok := any(&v).(encoding.TextUnmarshaler); ok {
+		err := u.UnmarshalText([]byte(s))
+		return v, err
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() == reflect.String {
+		rv.SetString(s)
+		return v, nil
+	}
+
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return v, fmt.Errorf("mapset: cannot parse %q as %T: %w", s, v, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+/*
+Open Source Initiative OSI - The MIT License (MIT):Licensing
+
+The MIT License (MIT)
+Copyright (c) 2013 - 2022 Ralph Caraveo (deckarep@gmail.com)
+
+Permission is hereby granted, free of charge, to any person obtaining a copy of
+this software and associated documentation files (the "Software"), to deal in
+the Software without restriction, including without limitation the rights to
+use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies
+of the Software, and to permit persons to whom the Software is furnished to do
+so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package mapset
+
+import (
+	"encoding"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ encoding.TextMarshaler   = NewSet[String]()
+	_ encoding.TextUnmarshaler = NewSet[String]()
+)
+
+// upper is stored in upper case and parsed through encoding.TextUnmarshaler.
+type upper string
+
+func (u *upper) UnmarshalText(text []byte) error {
+	*u = upper(strings.ToUpper(string(text)))
+	return nil
+}
+
+func Test_MarshalText(t *testing.T) {
+	r := require.New(t)
+
+	b, err := NewOrderedSet[String]("eu", "us", "a,b", `"q"`, " lead").MarshalText()
+	r.NoError(err)
+	r.Equal(`eu,us,"a,b","""q"""," lead"`, string(b))
+
+	b, err = NewOrderedSet[Int](3, 1, 2).MarshalText()
+	r.NoError(err)
+	r.Equal(`3,1,2`, string(b))
+
+	b, err = NewSet[String]().MarshalText()
+	r.NoError(err)
+	r.Equal(``, string(b))
+
+	b, err = NewSet[String]("").MarshalText()
+	r.NoError(err)
+	r.Equal(`""`, string(b))
+
+	b, err = Canonical(NewSet[String]("b", "c", "a")).MarshalText()
+	r.NoError(err)
+	r.Equal(`a,b,c`, string(b))
+}
+
+func Test_UnmarshalText(t *testing.T) {
+	r := require.New(t)
+
+	s := NewOrderedSet[String]("stale")
+	r.NoError(s.UnmarshalText([]byte(`eu, us,"a,b","""q"""," lead"`)))
+	r.Equal([]String{"eu", "us", "a,b", `"q"`, " lead"}, s.ToSlice())
+
+	r.NoError(s.UnmarshalText([]byte(``)))
+	r.Zero(s.Cardinality())
+
+	r.NoError(s.UnmarshalText([]byte(`""`)))
+	r.Equal([]String{""}, s.ToSlice())
+
+	ints := NewThreadUnsafeSet[Int]()
+	r.NoError(ints.UnmarshalText([]byte(`1,2,3`)))
+	assertEqual(ints, NewSet[Int](1, 2, 3), r)
+	r.Error(ints.UnmarshalText([]byte(`4,x`)))
+	assertEqual(ints, NewSet[Int](1, 2, 3), r)
+
+	comparable := NewComparableSet[upper]()
+	r.NoError(comparable.UnmarshalText([]byte(`eu,us`)))
+	r.True(comparable.Contains("EU", "US"))
+
+	r.Error(s.UnmarshalText([]byte(`"unterminated`)))
+	r.Equal([]String{""}, s.ToSlice(), "a failed unmarshal should leave the set unchanged")
+}
+
+func Test_TextRoundTrip(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[String]("plain", "with,comma", `with"quote`, "with\nnewline", "  spaces  ", "")
+	b, err := s.MarshalText()
+	r.NoError(err)
+
+	d := NewSet[String]()
+	r.NoError(d.UnmarshalText(b))
+	assertEqual(s, d, r)
+}
@@ -25,55 +25,179 @@ SOFTWARE.
 
 package mapset
 
-import "sync"
-
-type threadSafeSet[T EqualKeyer] struct {
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// threadSafeSet guards a thread-unsafe set of any implementation with a
+// sync.RWMutex.
+//
+// Iterations run over a snapshot of the set without holding the lock, so
+// they never block writers. Snapshots are copy-on-write: uss is shared with
+// the running iterations, counted by iterating, and only cloned when it is
+// about to be modified while any of them is still in progress.
+//
+// Operations involving two thread-safe sets lock them in the order of
+// their ids, so that concurrent a.Union(b) and b.Union(a) cannot deadlock
+// behind a pending writer.
+type threadSafeSet[T any] struct {
 	sync.RWMutex
-	uss threadUnsafeSet[T]
+	id        uint64
+	uss       Set[T]
+	iterating *int32
+}
+
+// lastSetID is the id most recently assigned to a threadSafeSet.
+var lastSetID uint64
+
+// init prepares s for guarding uss.
+func (s *threadSafeSet[T]) init(uss Set[T]) {
+	s.id = atomic.AddUint64(&lastSetID, 1)
+	s.uss = uss
+	s.iterating = new(int32)
 }
 
-func newThreadSafeSet[T EqualKeyer]() threadSafeSet[T] {
-	newUss := newThreadUnsafeSet[T]()
-	return threadSafeSet[T]{
-		uss: newUss,
+// threadSafeWrapper is implemented by thread-unsafe sets that offer more
+// operations than Set and therefore need a dedicated thread-safe wrapper.
+type threadSafeWrapper[T any] interface {
+	threadSafe() Set[T]
+}
+
+// lockedSet is implemented by threadSafeSet and every wrapper embedding it.
+type lockedSet[T any] interface {
+	locked() *threadSafeSet[T]
+}
+
+// newThreadSafeSet wraps uss into the thread-safe set matching its
+// implementation. uss must not be used directly afterwards.
+func newThreadSafeSet[T any](uss Set[T]) Set[T] {
+	if w, ok := uss.(threadSafeWrapper[T]); ok {
+		return w.threadSafe()
 	}
+	s := &threadSafeSet[T]{}
+	s.init(uss)
+	return s
 }
 
-func (s *threadSafeSet[T]) Add(v T) bool {
+func (s *threadSafeSet[T]) locked() *threadSafeSet[T] {
+	return s
+}
+
+// emptySet returns an empty thread-safe set of the implementation of s.
+func (s *threadSafeSet[T]) emptySet() Set[T] {
+	s.RLock()
+	defer s.RUnlock()
+	return newThreadSafeSet(emptyClone(s.uss))
+}
+
+// lock acquires the write lock. If iterations over the current set are
+// still in progress, the set is cloned first so that they keep seeing it
+// as it was when they started.
+func (s *threadSafeSet[T]) lock() {
 	s.Lock()
-	ret := s.uss.Add(v)
-	s.Unlock()
-	return ret
+	if atomic.LoadInt32(s.iterating) > 0 {
+		s.uss = s.uss.Clone()
+		s.iterating = new(int32)
+	}
 }
 
-func (s *threadSafeSet[T]) Contains(v ...T) bool {
+// snapshot returns the current set for iterating over it without holding
+// the lock, along with a func to call once the iteration is done. The
+// returned set must not be modified.
+func (s *threadSafeSet[T]) snapshot() (Set[T], func()) {
 	s.RLock()
-	ret := s.uss.Contains(v...)
+	uss, iterating := s.uss, s.iterating
+	atomic.AddInt32(iterating, 1)
 	s.RUnlock()
-	return ret
+
+	return uss, func() {
+		atomic.AddInt32(iterating, -1)
+	}
 }
 
-func (s *threadSafeSet[T]) IsSubset(other Set[T]) bool {
-	o := other.(*threadSafeSet[T])
+func (s *threadSafeSet[T]) Add(v T) bool {
+	s.lock()
+	defer s.Unlock()
+	return s.uss.Add(v)
+}
 
-	s.RLock()
-	o.RLock()
+// AddWith calls merge with the set locked, so merge must not use the set.
+func (s *threadSafeSet[T]) AddWith(v T, merge MergeFunc[T]) bool {
+	s.lock()
+	defer s.Unlock()
+	return s.uss.AddWith(v, merge)
+}
 
-	ret := s.uss.IsSubset(&o.uss)
-	s.RUnlock()
-	o.RUnlock()
-	return ret
+func (s *threadSafeSet[T]) checkedAdd(v T, unique bool) (bool, error) {
+	s.lock()
+	defer s.Unlock()
+	if c, ok := s.uss.(checkedAdder[T]); ok {
+		return c.checkedAdd(v, unique)
+	}
+	return s.uss.Add(v), nil
 }
 
-func (s *threadSafeSet[T]) IsProperSubset(other Set[T]) bool {
-	o := other.(*threadSafeSet[T])
+func (s *threadSafeSet[T]) Replace(v T) (T, bool) {
+	s.lock()
+	defer s.Unlock()
+	return s.uss.Replace(v)
+}
 
+func (s *threadSafeSet[T]) Contains(v ...T) bool {
 	s.RLock()
 	defer s.RUnlock()
-	o.RLock()
-	defer o.RUnlock()
+	return s.uss.Contains(v...)
+}
+
+// rlockWith prepares a binary operation of s with other. It returns the
+// sets the operation should be performed on in place of s and other, along
+// with a func to call once it is done.
+//
+// If other is a threadSafeSet as well, both are read-locked in the order of
+// their ids. Sets of any other implementation, including wrappers of
+// thread-safe sets such as CanonicalSet, take care of their own
+// synchronization and may lock s again. They are therefore combined with a
+// snapshot of s instead, so that no lock of s is held while calling them.
+func (s *threadSafeSet[T]) rlockWith(other Set[T]) (Set[T], Set[T], func()) {
+	l, ok := other.(lockedSet[T])
+	if !ok {
+		uss, done := s.snapshot()
+		return uss, other, done
+	}
+
+	o := l.locked()
+	if o == s {
+		// Read-locking s twice could deadlock behind a pending writer.
+		s.RLock()
+		return s.uss, s.uss, s.RUnlock
+	}
+
+	first, second := s, o
+	if o.id < s.id {
+		first, second = o, s
+	}
+	first.RLock()
+	second.RLock()
+	return s.uss, o.uss, func() {
+		second.RUnlock()
+		first.RUnlock()
+	}
+}
+
+func (s *threadSafeSet[T]) IsSubset(other Set[T]) bool {
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
 
-	return s.uss.IsProperSubset(&o.uss)
+	return uss.IsSubset(o)
+}
+
+func (s *threadSafeSet[T]) IsProperSubset(other Set[T]) bool {
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
+
+	return uss.IsProperSubset(o)
 }
 
 func (s *threadSafeSet[T]) IsSuperset(other Set[T]) bool {
@@ -85,137 +209,134 @@ func (s *threadSafeSet[T]) IsProperSuperset(other Set[T]) bool {
 }
 
 func (s *threadSafeSet[T]) Union(other Set[T]) Set[T] {
-	o := other.(*threadSafeSet[T])
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
 
-	s.RLock()
-	o.RLock()
+	return newThreadSafeSet(uss.Union(o))
+}
 
-	unsafeUnion := s.uss.Union(&o.uss).(*threadUnsafeSet[T])
-	ret := &threadSafeSet[T]{uss: *unsafeUnion}
-	s.RUnlock()
-	o.RUnlock()
-	return ret
+// UnionWith calls merge with both sets locked, so merge must not use them.
+func (s *threadSafeSet[T]) UnionWith(other Set[T], merge MergeFunc[T]) Set[T] {
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
+
+	return newThreadSafeSet(uss.UnionWith(o, merge))
 }
 
 func (s *threadSafeSet[T]) Intersect(other Set[T]) Set[T] {
-	o := other.(*threadSafeSet[T])
-
-	s.RLock()
-	o.RLock()
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
 
-	unsafeIntersection := s.uss.Intersect(&o.uss).(*threadUnsafeSet[T])
-	ret := &threadSafeSet[T]{uss: *unsafeIntersection}
-	s.RUnlock()
-	o.RUnlock()
-	return ret
+	return newThreadSafeSet(uss.Intersect(o))
 }
 
 func (s *threadSafeSet[T]) Difference(other Set[T]) Set[T] {
-	o := other.(*threadSafeSet[T])
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
 
-	s.RLock()
-	o.RLock()
-
-	unsafeDifference := s.uss.Difference(&o.uss).(*threadUnsafeSet[T])
-	ret := &threadSafeSet[T]{uss: *unsafeDifference}
-	s.RUnlock()
-	o.RUnlock()
-	return ret
+	return newThreadSafeSet(uss.Difference(o))
 }
 
 func (s *threadSafeSet[T]) SymmetricDifference(other Set[T]) Set[T] {
-	o := other.(*threadSafeSet[T])
-
-	s.RLock()
-	o.RLock()
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
 
-	unsafeDifference := s.uss.SymmetricDifference(&o.uss).(*threadUnsafeSet[T])
-	ret := &threadSafeSet[T]{uss: *unsafeDifference}
-	s.RUnlock()
-	o.RUnlock()
-	return ret
+	return newThreadSafeSet(uss.SymmetricDifference(o))
 }
 
 func (s *threadSafeSet[T]) Clear() {
-	s.Lock()
-	s.uss = newThreadUnsafeSet[T]()
+	s.lock()
+	s.uss.Clear()
 	s.Unlock()
 }
 
 func (s *threadSafeSet[T]) Remove(v T) {
-	s.Lock()
-	delete(s.uss, v.Key())
-	s.Unlock()
+	s.lock()
+	defer s.Unlock()
+	s.uss.Remove(v)
 }
 
 func (s *threadSafeSet[T]) Cardinality() int {
 	s.RLock()
 	defer s.RUnlock()
-	return len(s.uss)
+	return s.uss.Cardinality()
 }
 
+// Each iterates over a snapshot of the set, cb may modify the set.
 func (s *threadSafeSet[T]) Each(cb func(T) bool) {
-	s.RLock()
-	for _, elem := range s.uss {
-		if cb(elem) {
-			break
-		}
-	}
-	s.RUnlock()
+	uss, done := s.snapshot()
+	defer done()
+	uss.Each(cb)
 }
 
+// Iter returns a channel yielding the elements of a snapshot of the set
+// taken when Iter is called. Draining it slowly does not block writers.
 func (s *threadSafeSet[T]) Iter() <-chan T {
+	uss, done := s.snapshot()
 	ch := make(chan T)
 	go func() {
-		s.RLock()
-
-		for _, elem := range s.uss {
+		defer done()
+		uss.Each(func(elem T) bool {
 			ch <- elem
-		}
+			return false
+		})
 		close(ch)
-		s.RUnlock()
 	}()
 
 	return ch
 }
 
+// Iterator returns an Iterator over a snapshot of the set taken when
+// Iterator is called. Draining it slowly does not block writers.
 func (s *threadSafeSet[T]) Iterator() *Iterator[T] {
+	uss, done := s.snapshot()
 	iterator, ch, stopCh := newIterator[T]()
 
 	go func() {
-		s.RLock()
-	L:
-		for _, elem := range s.uss {
+		defer done()
+		uss.Each(func(elem T) bool {
 			select {
 			case <-stopCh:
-				break L
+				return true
 			case ch <- elem:
+				return false
 			}
-		}
+		})
 		close(ch)
-		s.RUnlock()
 	}()
 
 	return iterator
 }
 
-func (s *threadSafeSet[T]) Equal(other Set[T]) bool {
-	o := other.(*threadSafeSet[T])
+// Pull takes a snapshot of the set under the read lock, which is released
+// again before Pull returns.
+func (s *threadSafeSet[T]) Pull(ctx context.Context) *PullIterator[T] {
+	return newPullIterator(ctx, s.ToSlice())
+}
 
-	s.RLock()
-	o.RLock()
+// All yields the elements of a snapshot of the set taken when the loop
+// starts, so the loop body may freely use and even modify the set.
+func (s *threadSafeSet[T]) All() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		uss, done := s.snapshot()
+		defer done()
+		uss.Each(func(elem T) bool {
+			return !yield(elem)
+		})
+	}
+}
 
-	ret := s.uss.Equal(&o.uss)
-	s.RUnlock()
-	o.RUnlock()
-	return ret
+func (s *threadSafeSet[T]) Equal(other Set[T]) bool {
+	uss, o, unlock := s.rlockWith(other)
+	defer unlock()
+
+	return uss.Equal(o)
 }
 
 func (s *threadSafeSet[T]) Clone() Set[T] {
 	s.RLock()
 
-	unsafeClone := s.uss.Clone().(*threadUnsafeSet[T])
-	ret := &threadSafeSet[T]{uss: *unsafeClone}
+	ret := newThreadSafeSet(s.uss.Clone())
 	s.RUnlock()
 	return ret
 }
@@ -228,17 +349,14 @@ func (s *threadSafeSet[T]) String() string {
 }
 
 func (s *threadSafeSet[T]) Pop() (T, bool) {
-	s.Lock()
+	s.lock()
 	defer s.Unlock()
 	return s.uss.Pop()
 }
 
 func (s *threadSafeSet[T]) ToSlice() []T {
-	elems := make([]T, 0, s.Cardinality())
 	s.RLock()
-	for _, elem := range s.uss {
-		elems = append(elems, elem)
-	}
+	elems := s.uss.ToSlice()
 	s.RUnlock()
 	return elems
 }
@@ -252,9 +370,21 @@ func (s *threadSafeSet[T]) MarshalJSON() ([]byte, error) {
 }
 
 func (s *threadSafeSet[T]) UnmarshalJSON(p []byte) error {
+	s.lock()
+	defer s.Unlock()
+	return s.uss.UnmarshalJSON(p)
+}
+
+func (s *threadSafeSet[T]) MarshalText() ([]byte, error) {
 	s.RLock()
-	err := s.uss.UnmarshalJSON(p)
+	b, err := s.uss.MarshalText()
 	s.RUnlock()
 
-	return err
+	return b, err
+}
+
+func (s *threadSafeSet[T]) UnmarshalText(text []byte) error {
+	s.lock()
+	defer s.Unlock()
+	return s.uss.UnmarshalText(text)
 }
@@ -32,6 +32,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -511,3 +512,263 @@ func Test_MarshalJSON(t *testing.T) {
 
 	r.Truef(expected.Equal(actual), "Expected no difference, got: %v", expected.Difference(actual))
 }
+
+func Test_EachMutating(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[Int](1, 2, 3)
+	seen := 0
+	s.Each(func(elem Int) bool {
+		seen++
+		s.Remove(elem)
+		s.Add(elem + 10)
+		return false
+	})
+
+	r.Equal(3, seen, "iteration must not see elements added during it")
+	assertEqual(s, NewSet[Int](11, 12, 13), r)
+}
+
+func Test_IterDoesNotBlockWriters(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSet[Int](1, 2, 3)
+	ch := s.Iter()
+	<-ch
+
+	// The channel is left undrained while the set is modified.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Add(4)
+		s.Remove(1)
+		s.Clear()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		r.FailNow("writers are blocked by an undrained Iter channel")
+	}
+
+	n := 1
+	for range ch {
+		n++
+	}
+	r.Equal(3, n, "iteration must see the set as it was when it started")
+	r.Zero(s.Cardinality())
+}
+
+func Test_IteratorSnapshot(t *testing.T) {
+	r := require.New(t)
+
+	s := NewSortedSet[Int](func(a, b Int) bool { return a < b }, 1, 2, 3)
+	it := s.Iterator()
+	r.Equal(Int(1), <-it.C)
+	s.Remove(2)
+	s.Add(0)
+
+	var rest []Int
+	for elem := range it.C {
+		rest = append(rest, elem)
+	}
+	r.Equal([]Int{2, 3}, rest)
+	r.Equal([]Int{0, 1, 3}, s.ToSlice())
+}
+
+func Test_SnapshotConcurrent(t *testing.T) {
+	r := require.New(t)
+	runtime.GOMAXPROCS(2)
+
+	s := NewSet[Int]()
+	for i := 0; i < N; i++ {
+		s.Add(Int(i))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := N; i < 2*N; i++ {
+			s.Add(Int(i))
+			s.Remove(Int(i - N))
+		}
+	}()
+	sizes := make([]int, 10)
+	go func() {
+		defer wg.Done()
+		for i := range sizes {
+			for range s.Iter() {
+				sizes[i]++
+			}
+		}
+	}()
+	wg.Wait()
+
+	// Each snapshot is taken either between or within a pair of Add and
+	// Remove.
+	for _, n := range sizes {
+		r.Truef(n == N || n == N+1, "snapshot holds %d elements", n)
+	}
+}
+
+// runWithTimeout fails the test if f does not return in time, which is
+// taken as a deadlock.
+func runWithTimeout(t *testing.T, f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("deadlock")
+	}
+}
+
+func Test_BinaryOpsLockOrder(t *testing.T) {
+	runtime.GOMAXPROCS(4)
+
+	a := NewSet[Int]()
+	b := NewSortedSet[Int](func(x, y Int) bool { return x < y })
+	for i := 0; i < 100; i++ {
+		a.Add(Int(i))
+		b.Add(Int(i + 50))
+	}
+
+	ops := []func(x, y Set[Int]){
+		func(x, y Set[Int]) { x.Union(y) },
+		func(x, y Set[Int]) { x.Intersect(y) },
+		func(x, y Set[Int]) { x.Difference(y) },
+		func(x, y Set[Int]) { x.SymmetricDifference(y) },
+		func(x, y Set[Int]) { x.Equal(y) },
+		func(x, y Set[Int]) { x.IsSubset(y) },
+		func(x, y Set[Int]) { x.IsProperSuperset(y) },
+		// Wrappers lock the wrapped set themselves.
+		func(x, y Set[Int]) { x.Union(Canonical(y)) },
+		func(x, y Set[Int]) { x.Equal(Canonical(y)) },
+		func(x, y Set[Int]) { x.IsSubset(Canonical(y)) },
+	}
+
+	runWithTimeout(t, func() {
+		var wg sync.WaitGroup
+		for _, op := range ops {
+			wg.Add(2)
+			go func(op func(x, y Set[Int])) {
+				defer wg.Done()
+				for i := 0; i < N; i++ {
+					op(a, b)
+				}
+			}(op)
+			go func(op func(x, y Set[Int])) {
+				defer wg.Done()
+				for i := 0; i < N; i++ {
+					op(b, a)
+				}
+			}(op)
+		}
+
+		// Writers make readers queue up behind them.
+		wg.Add(2)
+		for _, s := range []Set[Int]{a, b} {
+			go func(s Set[Int]) {
+				defer wg.Done()
+				for i := 0; i < N; i++ {
+					s.Add(Int(i % 200))
+					s.Remove(Int((i + 100) % 200))
+				}
+			}(s)
+		}
+		wg.Wait()
+	})
+}
+
+func Test_SelfOperations(t *testing.T) {
+	r := require.New(t)
+	runtime.GOMAXPROCS(4)
+
+	s := NewSet[Int](1, 2, 3)
+	r.True(s.Union(s).Equal(s))
+	r.True(s.Intersect(s).Equal(s))
+	r.Zero(s.Difference(s).Cardinality())
+	r.Zero(s.SymmetricDifference(s).Cardinality())
+	r.True(s.Equal(s))
+	r.True(s.IsSubset(s))
+	r.False(s.IsProperSubset(s))
+	r.True(s.IsSuperset(s))
+	r.True(s.Union(Canonical(s)).Equal(s))
+	r.True(s.Equal(Canonical(s)))
+	r.Zero(s.Difference(Canonical(s)).Cardinality())
+
+	runWithTimeout(t, func() {
+		var wg sync.WaitGroup
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				s.Union(s)
+				s.Equal(s)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				s.Union(Canonical(s))
+				s.Intersect(Canonical(s))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				s.IsSubset(s)
+				s.Intersect(s)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				s.Add(Int(i))
+				s.Remove(Int(i))
+			}
+		}()
+		wg.Wait()
+	})
+}
+
+func Test_BinaryOpsPanicUnlock(t *testing.T) {
+	r := require.New(t)
+
+	// Comparing the elements of a and b panics while both are locked.
+	a := NewSet(nilField{new(int)})
+	b := NewSet(nilField{})
+	ops := []func(){
+		func() { a.Union(b) },
+		func() { a.UnionWith(b, KeepExisting[nilField]) },
+		func() { a.Intersect(b) },
+		func() { a.Difference(b) },
+		func() { a.SymmetricDifference(b) },
+		func() { a.Equal(b) },
+		func() { a.IsSubset(b) },
+	}
+
+	var panicked int
+	runWithTimeout(t, func() {
+		for _, op := range ops {
+			func() {
+				defer func() {
+					if recover() != nil {
+						panicked++
+					}
+				}()
+				op()
+			}()
+			// Writers block if a lock is still held.
+			a.Clear()
+			b.Clear()
+			a.Add(nilField{new(int)})
+			b.Add(nilField{})
+		}
+	})
+	r.Equal(len(ops), panicked)
+}
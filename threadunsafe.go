@@ -26,13 +26,23 @@ SOFTWARE.
 package mapset
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"strings"
 )
 
-type threadUnsafeSet[T EqualKeyer] map[string]T
+// threadUnsafeSet stores its elements in buckets indexed by their key.
+// Elements whose keys collide but which are not equal share a bucket, so no
+// element is ever silently overwritten by another one. Keys and equality
+// are determined by the key and equal funcs, which call Key() and Equal
+// for Keyer elements. Add resolves conflicts between Equal elements with
+// merge, or replaces the stored element if merge is nil.
+type threadUnsafeSet[K comparable, T any] struct {
+	buckets map[K][]T
+	size    int
+	key     func(T) K
+	equal   func(a, b T) bool
+	merge   MergeFunc[T]
+}
 
 type String string
 
@@ -50,238 +60,259 @@ func (i String) Key() string {
 }
 
 // Assert concrete type:threadUnsafeSet adheres to Set interface.
-var _ Set[String] = (*threadUnsafeSet[String])(nil)
+var _ Set[String] = (*threadUnsafeSet[string, String])(nil)
 
-func newThreadUnsafeSet[T EqualKeyer]() threadUnsafeSet[T] {
-	return make(threadUnsafeSet[T])
+func newThreadUnsafeSet[K comparable, T any](key func(T) K, equal func(a, b T) bool) threadUnsafeSet[K, T] {
+	return threadUnsafeSet[K, T]{
+		buckets: make(map[K][]T),
+		key:     key,
+		equal:   equal,
+	}
 }
 
-func (s *threadUnsafeSet[T]) Add(v T) bool {
-	prevLen := len(*s)
-	(*s)[v.Key()] = v
-	return prevLen != len(*s)
+// newThreadUnsafeKeyerSet returns a threadUnsafeSet of Keyer elements.
+func newThreadUnsafeKeyerSet[K comparable, T Keyer[K]]() threadUnsafeSet[K, T] {
+	return newThreadUnsafeSet(keyerKey[K, T], keyerEqual[K, T])
 }
 
-func (s *threadUnsafeSet[T]) Cardinality() int {
-	return len(*s)
+func keyerKey[K comparable, T Keyer[K]](v T) K {
+	return v.Key()
 }
 
-func (s *threadUnsafeSet[T]) Clear() {
-	*s = newThreadUnsafeSet[T]()
+func keyerEqual[K comparable, T Keyer[K]](a, b T) bool {
+	return a.Equal(b)
 }
 
-func (s *threadUnsafeSet[T]) Clone() Set[T] {
-	clonedSet := newThreadUnsafeSet[T]()
-	for _, elem := range *s {
-		clonedSet.Add(elem)
+// find returns the key of v and the position of v within its bucket,
+// the position is -1 when v is not in the set.
+func (s *threadUnsafeSet[K, T]) find(v T) (K, int) {
+	key := s.key(v)
+	for i, elem := range s.buckets[key] {
+		if s.equal(elem, v) {
+			return key, i
+		}
 	}
-	return &clonedSet
+	return key, -1
 }
 
-func (s *threadUnsafeSet[T]) Contains(v ...T) bool {
-	for _, val := range v {
-		// TODO: key collision ?
-		if vSet, ok := (*s)[val.Key()]; !ok || !vSet.Equal(val) {
-			return false
-		}
+// removeAt removes the element at position i of the bucket stored under key.
+func (s *threadUnsafeSet[K, T]) removeAt(key K, i int) T {
+	bucket := s.buckets[key]
+	elem := bucket[i]
+	if len(bucket) == 1 {
+		delete(s.buckets, key)
+	} else {
+		s.buckets[key] = append(bucket[:i:i], bucket[i+1:]...)
 	}
-	return true
+	s.size--
+	return elem
 }
 
-func (s *threadUnsafeSet[T]) Difference(other Set[T]) Set[T] {
-	_ = other.(*threadUnsafeSet[T])
+func (s *threadUnsafeSet[K, T]) threadSafe() Set[T] {
+	ts := &threadSafeKeyerSet[K, T]{}
+	ts.init(s)
+	return ts
+}
 
-	diff := newThreadUnsafeSet[T]()
-	for _, elem := range *s {
-		if !other.Contains(elem) {
-			diff.Add(elem)
-		}
-	}
-	return &diff
+func (s *threadUnsafeSet[K, T]) empty() *threadUnsafeSet[K, T] {
+	e := newThreadUnsafeSet(s.key, s.equal)
+	e.merge = s.merge
+	return &e
 }
 
-func (s *threadUnsafeSet[T]) Each(cb func(T) bool) {
-	for _, elem := range *s {
-		if cb(elem) {
-			break
-		}
+func (s *threadUnsafeSet[K, T]) emptySet() Set[T] {
+	return s.empty()
+}
+
+func (s *threadUnsafeSet[K, T]) replaceWith(src Set[T]) bool {
+	o, ok := src.(*threadUnsafeSet[K, T])
+	if ok {
+		*s = *o
 	}
+	return ok
 }
 
-func (s *threadUnsafeSet[T]) Equal(other Set[T]) bool {
-	_ = other.(*threadUnsafeSet[T])
+// merged returns the element to store in place of old when v is added.
+func (s *threadUnsafeSet[K, T]) merged(old, v T) T {
+	if s.merge == nil {
+		return v
+	}
+	return s.merge(old, v)
+}
 
-	if s.Cardinality() != other.Cardinality() {
+func (s *threadUnsafeSet[K, T]) Add(v T) bool {
+	key, i := s.find(v)
+	if i >= 0 {
+		s.buckets[key][i] = s.merged(s.buckets[key][i], v)
 		return false
 	}
-	for _, elem := range *s {
-		if !other.Contains(elem) {
-			return false
-		}
-	}
+	s.buckets[key] = append(s.buckets[key], v)
+	s.size++
 	return true
 }
 
-func (s *threadUnsafeSet[T]) Intersect(other Set[T]) Set[T] {
-	o := other.(*threadUnsafeSet[T])
+func (s *threadUnsafeSet[K, T]) checkedAdd(v T, unique bool) (bool, error) {
+	key, i := s.find(v)
+	if i >= 0 {
+		s.buckets[key][i] = s.merged(s.buckets[key][i], v)
+		return false, nil
+	}
+	if unique && len(s.buckets[key]) > 0 {
+		return false, fmt.Errorf("%w: %v collides with %v", ErrKeyCollision, v, s.buckets[key][0])
+	}
+	s.buckets[key] = append(s.buckets[key], v)
+	s.size++
+	return true, nil
+}
 
-	intersection := newThreadUnsafeSet[T]()
-	// loop over smaller set
-	if s.Cardinality() < other.Cardinality() {
-		for _, elem := range *s {
-			if other.Contains(elem) {
-				intersection.Add(elem)
-			}
-		}
-	} else {
-		for _, elem := range *o {
-			if s.Contains(elem) {
-				intersection.Add(elem)
-			}
-		}
+func (s *threadUnsafeSet[K, T]) AddWith(v T, merge MergeFunc[T]) bool {
+	key, i := s.find(v)
+	if i >= 0 {
+		s.buckets[key][i] = merge(s.buckets[key][i], v)
+		return false
 	}
-	return &intersection
+	s.buckets[key] = append(s.buckets[key], v)
+	s.size++
+	return true
 }
 
-func (s *threadUnsafeSet[T]) IsProperSubset(other Set[T]) bool {
-	return s.IsSubset(other) && !s.Equal(other)
+func (s *threadUnsafeSet[K, T]) Replace(v T) (T, bool) {
+	return replace[T](s, v)
 }
 
-func (s *threadUnsafeSet[T]) IsProperSuperset(other Set[T]) bool {
-	return s.IsSuperset(other) && !s.Equal(other)
+func (s *threadUnsafeSet[K, T]) Cardinality() int {
+	return s.size
 }
 
-func (s *threadUnsafeSet[T]) IsSubset(other Set[T]) bool {
-	_ = other.(*threadUnsafeSet[T])
-	if s.Cardinality() > other.Cardinality() {
-		return false
+func (s *threadUnsafeSet[K, T]) Clear() {
+	s.buckets = make(map[K][]T)
+	s.size = 0
+}
+
+func (s *threadUnsafeSet[K, T]) Clone() Set[T] {
+	clonedSet := newThreadUnsafeSet(s.key, s.equal)
+	clonedSet.merge = s.merge
+	for key, bucket := range s.buckets {
+		clonedSet.buckets[key] = append([]T(nil), bucket...)
 	}
-	for _, elem := range *s {
-		if !other.Contains(elem) {
+	clonedSet.size = s.size
+	return &clonedSet
+}
+
+func (s *threadUnsafeSet[K, T]) Contains(v ...T) bool {
+	for _, val := range v {
+		if _, i := s.find(val); i < 0 {
 			return false
 		}
 	}
 	return true
 }
 
-func (s *threadUnsafeSet[T]) IsSuperset(other Set[T]) bool {
-	return other.IsSubset(s)
+func (s *threadUnsafeSet[K, T]) Difference(other Set[T]) Set[T] {
+	return difference[T](s.empty(), s, other)
 }
 
-func (s *threadUnsafeSet[T]) Iter() <-chan T {
-	ch := make(chan T)
-	go func() {
-		for _, elem := range *s {
-			ch <- elem
+func (s *threadUnsafeSet[K, T]) Each(cb func(T) bool) {
+	for _, bucket := range s.buckets {
+		for _, elem := range bucket {
+			if cb(elem) {
+				return
+			}
 		}
-		close(ch)
-	}()
+	}
+}
 
-	return ch
+func (s *threadUnsafeSet[K, T]) Equal(other Set[T]) bool {
+	return equal[T](s, other)
 }
 
-func (s *threadUnsafeSet[T]) Iterator() *Iterator[T] {
-	iterator, ch, stopCh := newIterator[T]()
+func (s *threadUnsafeSet[K, T]) Intersect(other Set[T]) Set[T] {
+	return intersect[T](s.empty(), s, other)
+}
 
-	go func() {
-	L:
-		for _, elem := range *s {
-			select {
-			case <-stopCh:
-				break L
-			case ch <- elem:
-			}
-		}
-		close(ch)
-	}()
+func (s *threadUnsafeSet[K, T]) IsProperSubset(other Set[T]) bool {
+	return s.IsSubset(other) && !s.Equal(other)
+}
 
-	return iterator
+func (s *threadUnsafeSet[K, T]) IsProperSuperset(other Set[T]) bool {
+	return s.IsSuperset(other) && !s.Equal(other)
 }
 
-// TODO: how can we make this properly , return T but can't return nil.
-func (s *threadUnsafeSet[T]) Pop() (v T, ok bool) {
-	for key, item := range *s {
-		delete(*s, key)
-		return item, true
-	}
-	return
+func (s *threadUnsafeSet[K, T]) IsSubset(other Set[T]) bool {
+	return isSubset[T](s, other)
 }
 
-func (s *threadUnsafeSet[T]) Remove(v T) {
-	delete(*s, v.Key())
+func (s *threadUnsafeSet[K, T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
 }
 
-func (s *threadUnsafeSet[T]) String() string {
-	items := make([]string, 0, len(*s))
+func (s *threadUnsafeSet[K, T]) Iter() <-chan T {
+	return iterChan[T](s)
+}
 
-	for _, elem := range *s {
-		items = append(items, fmt.Sprintf("%v", elem))
-	}
-	return fmt.Sprintf("Set{%s}", strings.Join(items, ", "))
+func (s *threadUnsafeSet[K, T]) Iterator() *Iterator[T] {
+	return iterator[T](s)
 }
 
-func (s *threadUnsafeSet[T]) SymmetricDifference(other Set[T]) Set[T] {
-	_ = other.(*threadUnsafeSet[T])
+func (s *threadUnsafeSet[K, T]) Pull(ctx context.Context) *PullIterator[T] {
+	return newPullIterator(ctx, s.ToSlice())
+}
 
-	a := s.Difference(other)
-	b := other.Difference(s)
-	return a.Union(b)
+func (s *threadUnsafeSet[K, T]) All() func(yield func(T) bool) {
+	return all[T](s)
 }
 
-func (s *threadUnsafeSet[T]) ToSlice() []T {
-	elems := make([]T, 0, s.Cardinality())
-	for _, elem := range *s {
-		elems = append(elems, elem)
+// TODO: how can we make this properly , return T but can't return nil.
+func (s *threadUnsafeSet[K, T]) Pop() (v T, ok bool) {
+	for key, bucket := range s.buckets {
+		return s.removeAt(key, len(bucket)-1), true
 	}
-
-	return elems
+	return
 }
 
-func (s *threadUnsafeSet[T]) Union(other Set[T]) Set[T] {
-	o := other.(*threadUnsafeSet[T])
+func (s *threadUnsafeSet[K, T]) Remove(v T) {
+	if key, i := s.find(v); i >= 0 {
+		s.removeAt(key, i)
+	}
+}
 
-	unionedSet := newThreadUnsafeSet[T]()
+func (s *threadUnsafeSet[K, T]) String() string {
+	return toString[T](s)
+}
 
-	for _, elem := range *s {
-		unionedSet.Add(elem)
-	}
-	for _, elem := range *o {
-		unionedSet.Add(elem)
-	}
-	return &unionedSet
+func (s *threadUnsafeSet[K, T]) SymmetricDifference(other Set[T]) Set[T] {
+	return symmetricDifference[T](s.empty(), s, other)
 }
 
-// MarshalJSON creates a JSON array from the set, it marshals all elements
-func (s *threadUnsafeSet[T]) MarshalJSON() ([]byte, error) {
-	items := make([]string, 0, s.Cardinality())
+func (s *threadUnsafeSet[K, T]) ToSlice() []T {
+	return toSlice[T](s)
+}
 
-	for _, elem := range *s {
-		b, err := json.Marshal(elem)
-		if err != nil {
-			return nil, err
-		}
+func (s *threadUnsafeSet[K, T]) Union(other Set[T]) Set[T] {
+	return addAll(s.Clone(), other)
+}
 
-		items = append(items, string(b))
-	}
+func (s *threadUnsafeSet[K, T]) UnionWith(other Set[T], merge MergeFunc[T]) Set[T] {
+	return unionWith(s.Clone(), other, merge)
+}
 
-	return []byte(fmt.Sprintf("[%s]", strings.Join(items, ","))), nil
+// MarshalJSON creates a JSON array from the set, it marshals all elements
+func (s *threadUnsafeSet[K, T]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[T](s)
 }
 
 // UnmarshalJSON recreates a set from a JSON array, it only decodes
 // primitive types. Numbers are decoded as json.Number.
-func (s *threadUnsafeSet[T]) UnmarshalJSON(b []byte) error {
-	var i []T
-
-	d := json.NewDecoder(bytes.NewReader(b))
-	d.UseNumber()
-	err := d.Decode(&i)
-	if err != nil {
-		return err
-	}
+func (s *threadUnsafeSet[K, T]) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON[T](s, b)
+}
 
-	for _, v := range i {
-		s.Add(v)
-	}
+// MarshalText creates a comma separated list of the elements of the set.
+func (s *threadUnsafeSet[K, T]) MarshalText() ([]byte, error) {
+	return marshalText[T](s)
+}
 
-	return nil
+// UnmarshalText recreates a set from a comma separated list.
+func (s *threadUnsafeSet[K, T]) UnmarshalText(text []byte) error {
+	return unmarshalText[T](s, text)
 }